@@ -0,0 +1,409 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"ragie/pkg/client"
+)
+
+// fakeAPI is an in-memory stand-in for the Ragie documents API
+type fakeAPI struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu          sync.Mutex
+	docs        []*fakeDocument
+	nextID      int
+	requests    []string
+	failing     map[string]bool // Names of documents whose upload or update fails
+	failList    bool            // Whether listing documents fails
+	undeletable map[string]bool // IDs of documents whose deletion fails
+
+	// Status that documents with the given name reach once processed, instead of ready
+	processedAs map[string]string
+}
+
+// fakeDocument is pending when it is created and processed once it was polled twice
+type fakeDocument struct {
+	client.Document
+	Partition string
+	Content   string
+	polls     int
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	f := &fakeAPI{t: t}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAPI) client() *client.Client {
+	return client.NewClient("test-key", client.WithBaseURL(f.server.URL), client.WithMaxRetries(0))
+}
+
+// add stores a document as if it had been imported earlier and returns its ID
+func (f *fakeAPI) add(partition string, name string, metadata map[string]interface{}) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.create(partition, name, "", metadata).ID
+}
+
+// documents returns the documents with the given external ID
+func (f *fakeAPI) documents(externalID string) []*fakeDocument {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var docs []*fakeDocument
+	for _, doc := range f.docs {
+		if doc.Metadata["external_id"] == externalID {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// count returns the number of requests whose "METHOD /path" starts with prefix
+func (f *fakeAPI) count(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, r := range f.requests {
+		if strings.HasPrefix(r, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// failUploads makes uploads and updates of documents with the given name fail with a
+// server error
+func (f *fakeAPI) failUploads(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failing == nil {
+		f.failing = map[string]bool{}
+	}
+	f.failing[name] = true
+}
+
+// failListing makes listing documents fail with a server error
+func (f *fakeAPI) failListing() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failList = true
+}
+
+// failDelete makes deleting the document with the given ID fail with a server error
+func (f *fakeAPI) failDelete(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.undeletable == nil {
+		f.undeletable = map[string]bool{}
+	}
+	f.undeletable[id] = true
+}
+
+// processAs makes the processing of documents with the given name end in status, e.g.
+// "failed", or never end if status is "pending"
+func (f *fakeAPI) processAs(name string, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.processedAs == nil {
+		f.processedAs = map[string]string{}
+	}
+	f.processedAs[name] = status
+}
+
+func (f *fakeAPI) create(partition, name, content string, metadata map[string]interface{}) *fakeDocument {
+	f.nextID++
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	doc := &fakeDocument{
+		Document:  client.Document{ID: fmt.Sprintf("doc-%d", f.nextID), Name: name, Metadata: metadata, Status: "pending"},
+		Partition: partition,
+		Content:   content,
+	}
+	f.docs = append(f.docs, doc)
+	return doc
+}
+
+func (f *fakeAPI) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/documents":
+		f.list(w, r)
+	case r.Method == "POST" && r.URL.Path == "/documents/raw":
+		var payload struct {
+			Name      string                 `json:"name"`
+			Data      string                 `json:"data"`
+			Metadata  map[string]interface{} `json:"metadata"`
+			Partition string                 `json:"partition"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		doc := f.create(payload.Partition, payload.Name, payload.Data, payload.Metadata)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(doc.Document)
+	case r.Method == "POST" && r.URL.Path == "/documents":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		if f.failing[r.FormValue("name")] {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"detail": "Internal Server Error"}`)
+			return
+		}
+		var metadata map[string]interface{}
+		json.Unmarshal([]byte(r.FormValue("metadata")), &metadata)
+		doc := f.create(r.FormValue("partition"), r.FormValue("name"), string(content), metadata)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(doc.Document)
+	case (r.Method == "PUT" || r.Method == "PATCH") && strings.HasPrefix(r.URL.Path, "/documents/"):
+		f.update(w, r)
+	case r.Method == "POST" && r.URL.Path == "/retrievals":
+		f.retrieve(w, r)
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/documents/"):
+		id := strings.TrimPrefix(r.URL.Path, "/documents/")
+		for _, doc := range f.docs {
+			if doc.ID == id {
+				doc.polls++
+				if doc.polls >= 2 {
+					doc.Status = client.DocumentStatusReady
+					if status, ok := f.processedAs[doc.Name]; ok {
+						doc.Status = status
+					}
+				}
+				json.NewEncoder(w).Encode(doc.Document)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"detail": "Document not found"}`)
+	case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/documents/"):
+		id := strings.TrimPrefix(r.URL.Path, "/documents/")
+		if f.undeletable[id] {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"detail": "Internal Server Error"}`)
+			return
+		}
+		for i, doc := range f.docs {
+			if doc.ID == id {
+				f.docs = append(f.docs[:i], f.docs[i+1:]...)
+				json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"detail": "Document not found"}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"detail": "Not Found"}`)
+	}
+}
+
+func (f *fakeAPI) list(w http.ResponseWriter, r *http.Request) {
+	if f.failList {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"detail": "Internal Server Error"}`)
+		return
+	}
+
+	var filter map[string]interface{}
+	if raw := r.URL.Query().Get("filter"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &filter); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var matched []client.Document
+	for _, doc := range f.docs {
+		if doc.Partition != r.Header.Get("Partition") || !matchesFilter(doc.Metadata, filter) {
+			continue
+		}
+		matched = append(matched, doc.Document)
+	}
+
+	pageSize := 10
+	if n, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil && n > 0 {
+		pageSize = n
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+
+	var resp client.ListResponse
+	resp.Documents = []client.Document{}
+	if offset < len(matched) {
+		end := offset + pageSize
+		if end < len(matched) {
+			resp.Pagination.NextCursor = strconv.Itoa(end)
+		} else {
+			end = len(matched)
+		}
+		resp.Documents = matched[offset:end]
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// update replaces the content of a document, which is processed again, or patches its
+// metadata
+func (f *fakeAPI) update(w http.ResponseWriter, r *http.Request) {
+	id, what, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/documents/"), "/")
+	var doc *fakeDocument
+	for _, d := range f.docs {
+		if d.ID == id {
+			doc = d
+		}
+	}
+	if doc == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"detail": "Document not found"}`)
+		return
+	}
+	if f.failing[doc.Name] {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"detail": "Internal Server Error"}`)
+		return
+	}
+
+	switch what {
+	case "file":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		doc.Content = string(content)
+	case "raw":
+		var payload struct {
+			Data string `json:"data"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		doc.Content = payload.Data
+	case "metadata":
+		var payload struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		for key, value := range payload.Metadata {
+			if value == nil {
+				delete(doc.Metadata, key)
+			} else {
+				doc.Metadata[key] = value
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"metadata": doc.Metadata})
+		return
+	}
+
+	doc.Status = "pending"
+	doc.polls = 0
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// retrieve returns a chunk for each document whose content has words of the query,
+// scored by the share of the query words it has
+func (f *fakeAPI) retrieve(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Query     string                 `json:"query"`
+		TopK      int                    `json:"top_k"`
+		Filter    map[string]interface{} `json:"filter"`
+		Partition string                 `json:"partition"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	words := strings.Fields(strings.ToLower(payload.Query))
+	resp := client.RetrieveResponse{ScoredChunks: []client.ScoredChunk{}}
+	for _, doc := range f.docs {
+		if doc.Partition != payload.Partition || !matchesFilter(doc.Metadata, payload.Filter) {
+			continue
+		}
+		matched := 0
+		for _, word := range words {
+			if strings.Contains(strings.ToLower(doc.Content), word) {
+				matched++
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+		resp.ScoredChunks = append(resp.ScoredChunks, client.ScoredChunk{
+			ID:               doc.ID + "-chunk",
+			Text:             doc.Content,
+			Score:            float64(matched) / float64(len(words)),
+			DocumentID:       doc.ID,
+			DocumentName:     doc.Name,
+			DocumentMetadata: doc.Metadata,
+		})
+	}
+
+	sort.SliceStable(resp.ScoredChunks, func(i, j int) bool {
+		return resp.ScoredChunks[i].Score > resp.ScoredChunks[j].Score
+	})
+	if payload.TopK > 0 && len(resp.ScoredChunks) > payload.TopK {
+		resp.ScoredChunks = resp.ScoredChunks[:payload.TopK]
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// matchesFilter supports equality, $eq and $in conditions joined by an implicit $and
+func matchesFilter(metadata map[string]interface{}, filter map[string]interface{}) bool {
+	for key, cond := range filter {
+		value := fmt.Sprint(metadata[key])
+		if _, ok := metadata[key]; !ok {
+			value = ""
+		}
+
+		switch cond := cond.(type) {
+		case map[string]interface{}:
+			for op, operand := range cond {
+				switch op {
+				case "$eq":
+					if value != fmt.Sprint(operand) {
+						return false
+					}
+				case "$in":
+					found := false
+					for _, v := range operand.([]interface{}) {
+						if value == fmt.Sprint(v) {
+							found = true
+						}
+					}
+					if !found {
+						return false
+					}
+				}
+			}
+		default:
+			if value != fmt.Sprint(cond) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -1,7 +1,16 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"ragie/pkg/client"
 
@@ -9,55 +18,276 @@ import (
 	"github.com/spf13/viper"
 )
 
+// ClearConfig holds configuration for clear operations
+type ClearConfig struct {
+	DryRun    bool
+	Partition string
+	Filter    map[string]interface{} // Metadata filter of the documents to delete, all documents if empty
+	OlderThan time.Duration          // Only delete documents whose mod_time is older, disabled when 0
+	Yes       bool                   // Delete without asking for confirmation
+	Output    string
+	FailFast  bool
+
+	Concurrency int // Number of documents deleted in parallel
+}
+
+// defaultBatchConcurrency is the number of parallel requests of clear, update-metadata
+// and migrate when --concurrency is not set
+const defaultBatchConcurrency = 4
+
+// clearProgressInterval is the minimum time between two progress lines
+var clearProgressInterval = 2 * time.Second
+
+var (
+	clearFilter    string
+	clearWhere     []string
+	clearOlderThan string
+	clearYes       bool
+)
+
+// stdin is where confirmations are read from, replaced in tests
+var stdin io.Reader = os.Stdin
+
 var clearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear all documents",
 	Long: `Clear all documents from Ragie.
-If a partition is specified, only documents in that partition will be cleared.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Running clear...")
+If a partition is specified, only documents in that partition will be cleared.
 
-		c := client.NewClient(viper.GetString("api_key"))
-		opts := client.ListOptions{
-			Filter:    map[string]interface{}{},
-			PageSize:  100,
-			Partition: partition,
+Filtering:
+  --filter and --where select the documents to delete, as with 'documents list',
+  e.g. to remove a single zip import:
+    ragie clear --where source_type=zip --where zip_source=docs.zip
+  --older-than only deletes documents whose mod_time metadata is older than the
+  given age, such as 72h, 30d or 2w. Documents without a mod_time are kept.
+
+The matching documents are listed and counted first, and the deletion has to be
+confirmed unless --yes is passed, which is required when running without a
+terminal. Documents are then deleted in parallel, 4 at a time unless
+--concurrency is set, with a progress line every few seconds.
+
+A summary is printed at the end. If any document could not be deleted, the
+command exits with status 2, or stops at the first failure with --fail-fast.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := parseFilter(clearFilter, clearWhere)
+		if err != nil {
+			return err
 		}
 
-		for {
-			resp, err := c.ListDocuments(opts)
-			if err != nil {
-				return fmt.Errorf("failed to list documents: %v", err)
+		var olderThan time.Duration
+		if clearOlderThan != "" {
+			if olderThan, err = parseAge(clearOlderThan); err != nil {
+				return fmt.Errorf("invalid --older-than: %v", err)
 			}
+		}
 
-			if len(resp.Documents) == 0 {
-				break
-			}
+		return Clear(cmd.Context(), newClient(), ClearConfig{
+			DryRun:    dryRun,
+			Partition: partition,
+			Filter:    filter,
+			OlderThan: olderThan,
+			Yes:       clearYes,
+			Output:    outputFormat,
+			FailFast:  failFast,
 
-			for _, doc := range resp.Documents {
-				if dryRun {
-					fmt.Printf("would delete %s\n", doc.ID)
-					continue
-				}
+			Concurrency: batchConcurrency(),
+		})
+	},
+}
 
-				if err := c.DeleteDocument(doc.ID); err != nil {
-					fmt.Printf("error deleting document: %v\n", err)
-					continue
-				}
+func init() {
+	rootCmd.AddCommand(clearCmd)
+	clearCmd.Flags().StringVar(&clearFilter, "filter", "", "Only delete documents matching this metadata filter as JSON, e.g. '{\"source_type\": \"zip\"}'")
+	clearCmd.Flags().StringArrayVar(&clearWhere, "where", nil, "Only delete documents whose metadata matches key=value (repeatable)")
+	clearCmd.Flags().StringVar(&clearOlderThan, "older-than", "", "Only delete documents whose mod_time is older than this age, e.g. 72h, 30d or 2w")
+	clearCmd.Flags().BoolVarP(&clearYes, "yes", "y", false, "Delete without asking for confirmation")
+}
 
-				fmt.Printf("deleted %s\n", doc.ID)
-			}
+// Clear deletes the documents of the partition matching the filters of config, after
+// asking for confirmation unless config.Yes is set
+func Clear(ctx context.Context, c *client.Client, config ClearConfig) (err error) {
+	report, err := newReporter(config.Output, "clear", config.DryRun)
+	if err != nil {
+		return err
+	}
+	defer func() { err = report.Finish(err) }()
 
-			if resp.Pagination.NextCursor == "" {
-				break
-			}
-			opts.Cursor = resp.Pagination.NextCursor
+	report.Infof("Running clear...\n")
+
+	// List every matching document before deleting any, as deleting while paging
+	// through the results would shift the cursor
+	docs, err := listDocuments(ctx, c, client.ListOptions{Filter: config.Filter, Partition: config.Partition}, 0)
+	if err != nil {
+		return err
+	}
+	if config.OlderThan > 0 {
+		docs = modifiedBefore(docs, time.Now().Add(-config.OlderThan))
+	}
+
+	if len(docs) == 0 {
+		report.Infof("No documents to delete\n")
+		return nil
+	}
+
+	if !config.DryRun && !config.Yes {
+		ok, err := confirm(fmt.Sprintf("Delete %d documents from %s? [y/N] ", len(docs), clearScope(config)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("clear cancelled, no documents were deleted")
 		}
+	}
 
+	pool := newImportPool(ctx, ImportConfig{Concurrency: config.Concurrency})
+	lastProgress := time.Now()
+	for i, doc := range docs {
+		if err := pool.Go(func(ctx context.Context, out io.Writer) error {
+			return deleteDocument(ctx, c, config, report, doc, out)
+		}); err != nil {
+			break
+		}
+
+		// Progress is printed in order with the deletions, so it counts the documents
+		// whose outcome was printed before it
+		if done := i + 1; time.Since(lastProgress) >= clearProgressInterval && done < len(docs) {
+			lastProgress = time.Now()
+			pool.Print(func(out io.Writer) {
+				report.Textf(out, "progress: %d/%d documents (%d%%)\n", done, len(docs), done*100/len(docs))
+			})
+		}
+	}
+
+	return pool.Wait()
+}
+
+// deleteDocument deletes doc and reports the outcome. The returned error aborts the
+// clear, on a fatal error or the first failure with --fail-fast.
+func deleteDocument(ctx context.Context, c *client.Client, config ClearConfig, report *reporter, doc client.Document, out io.Writer) error {
+	start := time.Now()
+	e := event{Name: doc.Name, DocumentID: doc.ID}
+	if externalID, ok := doc.Metadata["external_id"].(string); ok {
+		e.ExternalID = externalID
+	}
+
+	if config.DryRun {
+		e.Action = actionDeleted
+		report.Item(out, e, "would delete %s\n", doc.ID)
 		return nil
-	},
+	}
+
+	if err := c.DeleteDocumentContext(ctx, doc.ID); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.DurationMS = since(start)
+		// Already gone, e.g. deleted concurrently or by an earlier retried request
+		if errors.Is(err, client.ErrNotFound) {
+			e.Action = actionSkipped
+			e.Message = "already deleted"
+			report.Item(out, e, "already deleted %s\n", doc.ID)
+			return nil
+		}
+		e.Action = actionFailed
+		e.Error = err.Error()
+		if errors.Is(err, client.ErrUnauthorized) {
+			report.Item(out, e, "")
+			return fmt.Errorf("failed to delete document %s: %w", doc.ID, err)
+		}
+		report.Item(out, e, "error deleting document: %v\n", err)
+		if config.FailFast {
+			return errFailFast
+		}
+		return nil
+	}
+
+	e.Action = actionDeleted
+	e.DurationMS = since(start)
+	report.Item(out, e, "deleted %s\n", doc.ID)
+	return nil
 }
 
-func init() {
-	rootCmd.AddCommand(clearCmd)
+// batchConcurrency returns --concurrency, or defaultBatchConcurrency if it was not set
+// by the flag, the environment or the profile, as deletes and metadata updates are
+// cheap to parallelize
+func batchConcurrency() int {
+	if !viper.IsSet("concurrency") {
+		return defaultBatchConcurrency
+	}
+	return concurrency
+}
+
+// clearScope describes the documents selected by config for the confirmation prompt
+func clearScope(config ClearConfig) string {
+	scope := "the default partition"
+	if config.Partition != "" {
+		scope = fmt.Sprintf("partition '%s'", config.Partition)
+	}
+	if len(config.Filter) > 0 {
+		filter, _ := json.Marshal(config.Filter)
+		scope += fmt.Sprintf(" matching %s", filter)
+	}
+	if config.OlderThan > 0 {
+		scope += fmt.Sprintf(" modified more than %v ago", config.OlderThan)
+	}
+	return scope
+}
+
+// confirm prints prompt to stderr and reports whether the answer read from stdin is yes
+func confirm(prompt string) (bool, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if err == io.EOF && answer == "" {
+		fmt.Fprintln(os.Stderr)
+		return false, fmt.Errorf("no confirmation received, pass --yes to delete without confirmation")
+	}
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// parseAge parses a duration such as 90m or 72h, also accepting days (30d) and weeks (2w)
+func parseAge(s string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(s)
+		if err == nil && d <= 0 {
+			err = fmt.Errorf("age must be positive, got '%s'", s)
+		}
+		return d, err
+	}
+
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid age '%s', expected e.g. 72h, 30d or 2w", s)
+	}
+	return time.Duration(n * float64(unit)), nil
+}
+
+// modifiedBefore returns the documents whose mod_time metadata is before cutoff.
+// Documents without a valid mod_time are left out.
+func modifiedBefore(docs []client.Document, cutoff time.Time) []client.Document {
+	var matched []client.Document
+	for _, doc := range docs {
+		value, _ := doc.Metadata["mod_time"].(string)
+		modTime, err := time.Parse(time.RFC3339, value)
+		if err != nil || !modTime.Before(cutoff) {
+			continue
+		}
+		matched = append(matched, doc)
+	}
+	return matched
 }
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age         string
+		expected    time.Duration
+		expectError bool
+	}{
+		{age: "90m", expected: 90 * time.Minute},
+		{age: "72h", expected: 72 * time.Hour},
+		{age: "30d", expected: 30 * 24 * time.Hour},
+		{age: "1.5d", expected: 36 * time.Hour},
+		{age: "2w", expected: 14 * 24 * time.Hour},
+		{age: "d", expectError: true},
+		{age: "-1d", expectError: true},
+		{age: "0s", expectError: true},
+		{age: "soon", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.age, func(t *testing.T) {
+			age, err := parseAge(tt.age)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error=%v, got %v", tt.expectError, err)
+			}
+			if age != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, age)
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	old := time.Now().Add(-60 * 24 * time.Hour).Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name        string
+		config      ClearConfig
+		input       string
+		expectError bool
+		expectedIDs []string // Documents left once done
+	}{
+		{
+			name:        "all documents",
+			config:      ClearConfig{Yes: true},
+			expectedIDs: []string{},
+		},
+		{
+			name:        "where",
+			config:      ClearConfig{Yes: true, Filter: map[string]interface{}{"source_type": "zip", "zip_source": "docs.zip"}},
+			expectedIDs: []string{"doc-1", "doc-2", "doc-4"},
+		},
+		{
+			name:        "older than",
+			config:      ClearConfig{Yes: true, OlderThan: 30 * 24 * time.Hour},
+			expectedIDs: []string{"doc-2", "doc-3", "doc-4"},
+		},
+		{
+			name:        "confirmed",
+			config:      ClearConfig{Filter: map[string]interface{}{"source_type": "zip"}},
+			input:       "y\n",
+			expectedIDs: []string{"doc-1", "doc-2"},
+		},
+		{
+			name:        "declined",
+			config:      ClearConfig{},
+			input:       "n\n",
+			expectError: true,
+			expectedIDs: []string{"doc-1", "doc-2", "doc-3", "doc-4"},
+		},
+		{
+			name:        "no confirmation",
+			config:      ClearConfig{},
+			expectError: true,
+			expectedIDs: []string{"doc-1", "doc-2", "doc-3", "doc-4"},
+		},
+		{
+			name:        "dry run",
+			config:      ClearConfig{DryRun: true},
+			expectedIDs: []string{"doc-1", "doc-2", "doc-3", "doc-4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureStdout(t)
+			previous := stdin
+			stdin = strings.NewReader(tt.input)
+			defer func() { stdin = previous }()
+
+			api := newFakeAPI(t)
+			api.add("", "a.txt", map[string]interface{}{"source_type": "files", "mod_time": old})
+			api.add("", "b.txt", map[string]interface{}{"source_type": "files", "mod_time": recent})
+			api.add("", "c.txt", map[string]interface{}{"source_type": "zip", "zip_source": "docs.zip", "mod_time": recent})
+			api.add("", "d.txt", map[string]interface{}{"source_type": "zip", "zip_source": "other.zip"})
+			api.add("staging", "e.txt", map[string]interface{}{"source_type": "files", "mod_time": old})
+
+			err := Clear(context.Background(), api.client(), tt.config)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error=%v, got %v", tt.expectError, err)
+			}
+
+			ids := []string{}
+			for _, doc := range api.docs {
+				if doc.Partition == "" {
+					ids = append(ids, doc.ID)
+				}
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expectedIDs, ",") {
+				t.Errorf("Expected documents %v to be left, got %v", tt.expectedIDs, ids)
+			}
+			if n := len(api.docs) - len(ids); n != 1 {
+				t.Errorf("Expected the other partition to be left alone, got %d documents", n)
+			}
+		})
+	}
+}
+
+func TestClearConcurrent(t *testing.T) {
+	defer func(interval time.Duration) { clearProgressInterval = interval }(clearProgressInterval)
+	clearProgressInterval = 0
+	out := captureStdout(t)
+
+	api := newFakeAPI(t)
+	for i := 0; i < 250; i++ {
+		api.add("", fmt.Sprintf("file-%d.txt", i), nil)
+	}
+
+	err := Clear(context.Background(), api.client(), ClearConfig{Yes: true, Concurrency: 8})
+	if err != nil {
+		t.Fatalf("Clear returned error: %v", err)
+	}
+
+	// Every page is listed before deleting, so no document is skipped by the cursor
+	if len(api.docs) != 0 {
+		t.Errorf("Expected all documents to be deleted, got %d left", len(api.docs))
+	}
+
+	// The output reads as a sequential run, with progress lines in between
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	deleted := 0
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "deleted "):
+			deleted++
+			if expected := fmt.Sprintf("deleted doc-%d", deleted); line != expected {
+				t.Fatalf("Expected '%s', got '%s'", expected, line)
+			}
+		case strings.HasPrefix(line, "progress: "):
+			if expected := fmt.Sprintf("progress: %d/250 documents", deleted); !strings.HasPrefix(line, expected) {
+				t.Errorf("Expected '%s', got '%s'", expected, line)
+			}
+		}
+	}
+	if deleted != 250 {
+		t.Errorf("Expected 250 deleted lines, got %d", deleted)
+	}
+}
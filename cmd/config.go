@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// settings maps the keys a profile can set to the flag that overrides them. Each
+// key can also be set with a RAGIE_ environment variable, e.g. RAGIE_BASE_URL.
+var settings = map[string]string{
+	"api_key":     "",
+	"base_url":    "base-url",
+	"partition":   "partition",
+	"mode":        "mode",
+	"delay":       "delay",
+	"rate":        "rate",
+	"concurrency": "concurrency",
+}
+
+// DefaultConfigPath returns the path of the configuration file,
+// $XDG_CONFIG_HOME/ragie/config.yaml or ~/.config/ragie/config.yaml
+func DefaultConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ragie", "config.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "ragie", "config.yaml")
+}
+
+// loadConfig binds the settings of v to flags and the environment, and adds the
+// values of the selected profile of the configuration file at path. Flags take
+// precedence over the environment, which takes precedence over the profile.
+//
+// The profile is the given name, RAGIE_PROFILE or the file's default_profile, in
+// that order. A missing file is only an error if a profile was asked for. The name
+// of the profile in use is returned, or "" if there is none.
+func loadConfig(v *viper.Viper, flags *pflag.FlagSet, path string, profile string) (string, error) {
+	for key, flag := range settings {
+		if f := flags.Lookup(flag); flag != "" && f != nil {
+			if err := v.BindPFlag(key, f); err != nil {
+				return "", err
+			}
+		}
+		if err := v.BindEnv(key, "RAGIE_"+strings.ToUpper(key)); err != nil {
+			return "", err
+		}
+	}
+
+	if profile == "" {
+		profile = os.Getenv("RAGIE_PROFILE")
+	}
+
+	if _, err := os.Stat(path); path == "" || os.IsNotExist(err) {
+		if profile != "" {
+			return "", fmt.Errorf("profile '%s' not found: no configuration file at %s", profile, path)
+		}
+		return "", nil
+	}
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType("yaml")
+	if err := file.ReadInConfig(); err != nil {
+		return "", fmt.Errorf("failed to read configuration file %s: %v", path, err)
+	}
+
+	if profile == "" {
+		profile = file.GetString("default_profile")
+		if profile == "" {
+			return "", nil
+		}
+	}
+
+	values, ok := file.GetStringMap("profiles")[strings.ToLower(profile)].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("profile '%s' not found in %s", profile, path)
+	}
+
+	// Catch typos such as api-key, which would otherwise be silently ignored
+	for key := range values {
+		if _, ok := settings[key]; !ok {
+			return "", fmt.Errorf("unknown setting '%s' in profile '%s', expected one of: %s", key, profile, strings.Join(settingKeys(), ", "))
+		}
+	}
+
+	if err := v.MergeConfigMap(values); err != nil {
+		return "", fmt.Errorf("failed to load profile '%s': %v", profile, err)
+	}
+
+	return profile, nil
+}
+
+func settingKeys() []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const testConfig = `
+default_profile: staging
+profiles:
+  staging:
+    api_key: staging-key
+    base_url: https://staging.example.com
+    partition: staging
+    concurrency: 4
+  production:
+    api_key: production-key
+    partition: production
+    mode: hi_res
+    delay: 0.5
+`
+
+func newTestSettingsFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("base-url", "https://api.ragie.ai", "")
+	flags.String("partition", "", "")
+	flags.String("mode", "", "")
+	flags.Float64("delay", 0, "")
+	flags.Float64("rate", 0, "")
+	flags.Int("concurrency", 1, "")
+	return flags
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		profile         string
+		env             map[string]string
+		args            []string
+		expectedProfile string
+		expected        map[string]interface{}
+	}{
+		{
+			name:            "default profile",
+			expectedProfile: "staging",
+			expected: map[string]interface{}{
+				"api_key": "staging-key", "base_url": "https://staging.example.com", "partition": "staging", "concurrency": 4,
+			},
+		},
+		{
+			name:            "profile from environment",
+			env:             map[string]string{"RAGIE_PROFILE": "production"},
+			expectedProfile: "production",
+			expected: map[string]interface{}{
+				"api_key": "production-key", "base_url": "https://api.ragie.ai", "mode": "hi_res", "delay": 0.5, "concurrency": 1,
+			},
+		},
+		{
+			name:            "profile flag over environment",
+			profile:         "staging",
+			env:             map[string]string{"RAGIE_PROFILE": "production"},
+			expectedProfile: "staging",
+			expected:        map[string]interface{}{"api_key": "staging-key"},
+		},
+		{
+			name:            "environment over profile",
+			env:             map[string]string{"RAGIE_API_KEY": "env-key", "RAGIE_PARTITION": "env-partition"},
+			expectedProfile: "staging",
+			expected:        map[string]interface{}{"api_key": "env-key", "partition": "env-partition", "concurrency": 4},
+		},
+		{
+			name:            "flags over environment",
+			env:             map[string]string{"RAGIE_PARTITION": "env-partition"},
+			args:            []string{"--partition", "flag-partition", "--concurrency", "8"},
+			expectedProfile: "staging",
+			expected:        map[string]interface{}{"partition": "flag-partition", "concurrency": 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RAGIE_PROFILE", "")
+			t.Setenv("RAGIE_API_KEY", "")
+			t.Setenv("RAGIE_PARTITION", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			flags := newTestSettingsFlags()
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+
+			v := viper.New()
+			name, err := loadConfig(v, flags, path, tt.profile)
+			if err != nil {
+				t.Fatalf("loadConfig returned error: %v", err)
+			}
+			if name != tt.expectedProfile {
+				t.Errorf("Expected profile '%s', got '%s'", tt.expectedProfile, name)
+			}
+
+			for key, expected := range tt.expected {
+				var got interface{}
+				switch expected.(type) {
+				case int:
+					got = v.GetInt(key)
+				case float64:
+					got = v.GetFloat64(key)
+				default:
+					got = v.GetString(key)
+				}
+				if got != expected {
+					t.Errorf("Expected %s to be %v, got %v", key, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	typoPath := filepath.Join(dir, "typo.yaml")
+	if err := os.WriteFile(typoPath, []byte("profiles:\n  dev:\n    api-key: x\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("RAGIE_PROFILE", "")
+
+	tests := []struct {
+		name        string
+		path        string
+		profile     string
+		expectError bool
+	}{
+		{name: "unknown profile", path: path, profile: "qa", expectError: true},
+		{name: "unknown setting", path: typoPath, profile: "dev", expectError: true},
+		{name: "profile without file", path: filepath.Join(dir, "missing.yaml"), profile: "staging", expectError: true},
+		{name: "no file", path: filepath.Join(dir, "missing.yaml"), profile: "", expectError: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadConfig(viper.New(), newTestSettingsFlags(), tt.path, tt.profile)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error, but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+		})
+	}
+}
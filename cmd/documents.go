@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ragie/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats only supported when listing documents
+const (
+	OutputTable = "table"
+	OutputCSV   = "csv"
+)
+
+var (
+	listFilter string
+	listWhere  []string
+	listLimit  int
+)
+
+var documentsCmd = &cobra.Command{
+	Use:   "documents",
+	Short: "Inspect and update documents",
+	Long:  `Inspect the documents stored in Ragie and update their metadata.`,
+}
+
+var documentsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List documents",
+	Long: `List the documents in a partition, following pagination until all matching
+documents, or --limit documents, are listed.
+
+Filtering:
+  --filter takes a Ragie metadata filter as JSON, e.g.
+    --filter '{"source_type": "files"}'
+    --filter '{"size": {"$gt": 1000}}'
+  --where adds an equality condition and can be repeated. The value is parsed as
+  JSON if it is a number, boolean or quoted string, and used as a string otherwise:
+    --where source_type=files --where size=42 --where 'code="007"'
+
+Output:
+  --output table (default), json, ndjson or csv. The csv output has a column
+  for each metadata key found in the listed documents.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := listOutputFormat(outputFormat)
+		if err != nil {
+			return err
+		}
+
+		filter, err := parseFilter(listFilter, listWhere)
+		if err != nil {
+			return err
+		}
+
+		opts := client.ListOptions{
+			Filter:    filter,
+			Partition: partition,
+		}
+		docs, err := listDocuments(cmd.Context(), newClient(), opts, listLimit)
+		if err != nil {
+			return err
+		}
+
+		return writeDocuments(stdout, format, docs)
+	},
+}
+
+var documentsGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Show a document and its processing status",
+	Long: `Show a document, including its processing status, chunk count, creation and
+update times and metadata.
+
+A document is processed after it is uploaded and goes through statuses such as
+pending, partitioned and indexed until it is ready, or failed. Use --output json
+for the full document.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return err
+		}
+
+		doc, err := newClient().GetDocumentContext(cmd.Context(), args[0])
+		if err != nil {
+			if errors.Is(err, client.ErrNotFound) {
+				return fmt.Errorf("document %s not found", args[0])
+			}
+			return fmt.Errorf("failed to get document %s: %w", args[0], err)
+		}
+
+		return writeDocument(stdout, outputFormat, doc)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(documentsCmd)
+	documentsCmd.AddCommand(documentsListCmd)
+	documentsCmd.AddCommand(documentsGetCmd)
+	documentsListCmd.Flags().StringVar(&listFilter, "filter", "", "Metadata filter as JSON, e.g. '{\"source_type\": \"files\"}'")
+	documentsListCmd.Flags().StringArrayVar(&listWhere, "where", nil, "Metadata equality condition as key=value (repeatable)")
+	documentsListCmd.Flags().IntVar(&listLimit, "limit", 0, "Maximum number of documents to list (0 lists all)")
+}
+
+// listOutputFormat maps --output to the format of a document listing, where text
+// means a table
+func listOutputFormat(format string) (string, error) {
+	switch format {
+	case "", OutputText, OutputTable:
+		return OutputTable, nil
+	case OutputJSON, OutputNDJSON, OutputCSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid output format '%s', must be one of: table, json, ndjson, csv", format)
+	}
+}
+
+// parseFilter combines a JSON metadata filter with key=value equality conditions
+func parseFilter(filterJSON string, where []string) (map[string]interface{}, error) {
+	filter := map[string]interface{}{}
+	if filterJSON != "" {
+		if err := json.Unmarshal([]byte(filterJSON), &filter); err != nil {
+			return nil, fmt.Errorf("invalid --filter, expected a JSON object: %v", err)
+		}
+	}
+
+	for _, condition := range where {
+		key, value, ok := strings.Cut(condition, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --where '%s', expected key=value", condition)
+		}
+		filter[key] = parseFilterValue(value)
+	}
+
+	return filter, nil
+}
+
+// parseFilterValue returns numbers, booleans and quoted strings as parsed JSON, and
+// anything else as a string
+func parseFilterValue(value string) interface{} {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+		switch parsed.(type) {
+		case float64, bool, string:
+			return parsed
+		}
+	}
+	return value
+}
+
+// listDocuments returns the documents matching opts, following the pagination
+// cursor until limit documents were found, or all of them if limit is 0
+func listDocuments(ctx context.Context, c *client.Client, opts client.ListOptions, limit int) ([]client.Document, error) {
+	opts.PageSize = 100
+	if limit > 0 && limit < opts.PageSize {
+		opts.PageSize = limit
+	}
+
+	docs := []client.Document{}
+	for {
+		resp, err := c.ListDocumentsContext(ctx, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to list documents: %w", err)
+		}
+
+		for _, doc := range resp.Documents {
+			docs = append(docs, doc)
+			if limit > 0 && len(docs) >= limit {
+				return docs, nil
+			}
+		}
+
+		if resp.Pagination.NextCursor == "" || len(resp.Documents) == 0 {
+			return docs, nil
+		}
+		opts.Cursor = resp.Pagination.NextCursor
+	}
+}
+
+// writeDocuments writes docs in the given listing format
+func writeDocuments(w io.Writer, format string, docs []client.Document) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+
+	case OutputNDJSON:
+		enc := json.NewEncoder(w)
+		for _, doc := range docs {
+			if err := enc.Encode(doc); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case OutputCSV:
+		keys := metadataKeys(docs)
+		cw := csv.NewWriter(w)
+		cw.Write(append([]string{"id", "name", "status"}, keys...))
+		for _, doc := range docs {
+			row := []string{doc.ID, doc.Name, doc.Status}
+			for _, key := range keys {
+				row = append(row, metadataString(doc.Metadata, key))
+			}
+			cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tSTATUS\tMETADATA")
+		for _, doc := range docs {
+			metadata, _ := json.Marshal(doc.Metadata)
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", doc.ID, doc.Name, doc.Status, metadata)
+		}
+		return tw.Flush()
+	}
+}
+
+// writeDocument writes a single document as JSON, or as one field per line with the
+// text format
+func writeDocument(w io.Writer, format string, doc *client.Document) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+
+	case OutputNDJSON:
+		return json.NewEncoder(w).Encode(doc)
+
+	default:
+		metadata, _ := json.Marshal(doc.Metadata)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "ID:\t%s\n", doc.ID)
+		fmt.Fprintf(tw, "Name:\t%s\n", doc.Name)
+		fmt.Fprintf(tw, "Status:\t%s\n", doc.Status)
+		fmt.Fprintf(tw, "Partition:\t%s\n", doc.Partition)
+		fmt.Fprintf(tw, "Chunks:\t%d\n", doc.ChunkCount)
+		fmt.Fprintf(tw, "Created:\t%s\n", formatTime(doc.CreatedAt))
+		fmt.Fprintf(tw, "Updated:\t%s\n", formatTime(doc.UpdatedAt))
+		fmt.Fprintf(tw, "Metadata:\t%s\n", metadata)
+		return tw.Flush()
+	}
+}
+
+// formatTime formats a timestamp in RFC 3339, or returns "" if it is unset
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// metadataKeys returns the sorted union of the metadata keys of docs
+func metadataKeys(docs []client.Document) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, doc := range docs {
+		for key := range doc.Metadata {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metadataString formats a metadata value for a table cell, as JSON unless it is a string
+func metadataString(metadata map[string]interface{}, key string) string {
+	value, ok := metadata[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
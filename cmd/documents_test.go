@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"ragie/pkg/client"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      string
+		where       []string
+		expected    map[string]interface{}
+		expectError bool
+	}{
+		{name: "empty", expected: map[string]interface{}{}},
+		{
+			name:     "json filter",
+			filter:   `{"size": {"$gt": 10}}`,
+			expected: map[string]interface{}{"size": map[string]interface{}{"$gt": float64(10)}},
+		},
+		{
+			name:     "where values",
+			where:    []string{"source_type=files", "size=42", "draft=true", `code="007"`, "path=a=b"},
+			expected: map[string]interface{}{"source_type": "files", "size": float64(42), "draft": true, "code": "007", "path": "a=b"},
+		},
+		{
+			name:     "where added to filter",
+			filter:   `{"source_type": "zip"}`,
+			where:    []string{"zip_source=docs.zip"},
+			expected: map[string]interface{}{"source_type": "zip", "zip_source": "docs.zip"},
+		},
+		{name: "invalid json", filter: `{"source_type":`, expectError: true},
+		{name: "where without value", where: []string{"source_type"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parseFilter(tt.filter, tt.where)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if !reflect.DeepEqual(filter, tt.expected) {
+				t.Errorf("Expected filter %v, got %v", tt.expected, filter)
+			}
+		})
+	}
+}
+
+func TestListDocuments(t *testing.T) {
+	api := newFakeAPI(t)
+	for i := 0; i < 250; i++ {
+		api.add("", fmt.Sprintf("file-%d.txt", i), map[string]interface{}{"source_type": "files"})
+	}
+	api.add("", "video", map[string]interface{}{"source_type": "youtube"})
+	api.add("staging", "staging.txt", map[string]interface{}{"source_type": "files"})
+
+	tests := []struct {
+		name          string
+		opts          client.ListOptions
+		limit         int
+		expectedCount int
+	}{
+		{name: "all pages", opts: client.ListOptions{Filter: map[string]interface{}{"source_type": "files"}}, expectedCount: 250},
+		{name: "limit across pages", opts: client.ListOptions{Filter: map[string]interface{}{"source_type": "files"}}, limit: 150, expectedCount: 150},
+		{name: "small limit", opts: client.ListOptions{}, limit: 5, expectedCount: 5},
+		{name: "partition", opts: client.ListOptions{Partition: "staging"}, expectedCount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := listDocuments(context.Background(), api.client(), tt.opts, tt.limit)
+			if err != nil {
+				t.Fatalf("listDocuments returned error: %v", err)
+			}
+			if len(docs) != tt.expectedCount {
+				t.Errorf("Expected %d documents, got %d", tt.expectedCount, len(docs))
+			}
+		})
+	}
+}
+
+func TestWriteDocuments(t *testing.T) {
+	docs := []client.Document{
+		{ID: "doc-1", Name: "a.txt", Status: "ready", Metadata: map[string]interface{}{"source_type": "files", "size": float64(3)}},
+		{ID: "doc-2", Name: "b, c.txt", Metadata: map[string]interface{}{"source_type": "files", "tags": []interface{}{"x"}}},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: OutputTable,
+			expected: "ID     NAME      STATUS  METADATA\n" +
+				"doc-1  a.txt     ready   {\"size\":3,\"source_type\":\"files\"}\n" +
+				"doc-2  b, c.txt          {\"source_type\":\"files\",\"tags\":[\"x\"]}\n",
+		},
+		{
+			format: OutputCSV,
+			expected: "id,name,status,size,source_type,tags\n" +
+				"doc-1,a.txt,ready,3,files,\n" +
+				"doc-2,\"b, c.txt\",,,files,\"[\"\"x\"\"]\"\n",
+		},
+		{
+			format: OutputNDJSON,
+			expected: `{"id":"doc-1","name":"a.txt","metadata":{"size":3,"source_type":"files"},"status":"ready"}` + "\n" +
+				`{"id":"doc-2","name":"b, c.txt","metadata":{"source_type":"files","tags":["x"]}}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeDocuments(&buf, tt.format, docs); err != nil {
+				t.Fatalf("writeDocuments returned error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected output:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteDocument(t *testing.T) {
+	doc := &client.Document{
+		ID:         "doc-1",
+		Name:       "a.txt",
+		Status:     "indexed",
+		Partition:  "staging",
+		ChunkCount: 4,
+		CreatedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Metadata:   map[string]interface{}{"source_type": "files"},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: OutputText,
+			expected: "ID:         doc-1\n" +
+				"Name:       a.txt\n" +
+				"Status:     indexed\n" +
+				"Partition:  staging\n" +
+				"Chunks:     4\n" +
+				"Created:    2025-01-02T03:04:05Z\n" +
+				"Updated:    \n" +
+				"Metadata:   {\"source_type\":\"files\"}\n",
+		},
+		{
+			format: OutputNDJSON,
+			expected: `{"id":"doc-1","name":"a.txt","metadata":{"source_type":"files"},"status":"indexed",` +
+				`"partition":"staging","chunk_count":4,"created_at":"2025-01-02T03:04:05Z"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeDocument(&buf, tt.format, doc); err != nil {
+				t.Fatalf("writeDocument returned error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected output:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestListOutputFormat(t *testing.T) {
+	tests := []struct {
+		format      string
+		expected    string
+		expectError bool
+	}{
+		{format: OutputText, expected: OutputTable},
+		{format: OutputTable, expected: OutputTable},
+		{format: OutputCSV, expected: OutputCSV},
+		{format: OutputJSON, expected: OutputJSON},
+		{format: "yaml", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			format, err := listOutputFormat(tt.format)
+			if (err != nil) != tt.expectError {
+				t.Errorf("Expected error=%v, got %v", tt.expectError, err)
+			}
+			if format != tt.expected {
+				t.Errorf("Expected format '%s', got '%s'", tt.expected, format)
+			}
+		})
+	}
+}
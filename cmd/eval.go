@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"ragie/pkg/client"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultEvalTopK is the number of chunks retrieved per query when neither the query
+// file nor --top-k set it
+const defaultEvalTopK = 8
+
+var (
+	evalTopK   int
+	evalFilter string
+	evalWhere  []string
+	evalRerank bool
+)
+
+// evalSet is a golden query set, read from a YAML or JSON file
+type evalSet struct {
+	TopK    int                    `yaml:"top_k"`
+	Filter  map[string]interface{} `yaml:"filter"`
+	Rerank  bool                   `yaml:"rerank"`
+	Queries []evalQuery            `yaml:"queries"`
+}
+
+// evalQuery is a query and the external IDs of the documents it should retrieve
+type evalQuery struct {
+	Query    string                 `yaml:"query"`
+	Expected []string               `yaml:"expected"`
+	Filter   map[string]interface{} `yaml:"filter"`
+}
+
+// evalResult scores the documents retrieved for a query against the expected ones
+type evalResult struct {
+	Type           string   `json:"type"`
+	Query          string   `json:"query"`
+	Expected       []string `json:"expected"`
+	Retrieved      []string `json:"retrieved"` // External IDs of the distinct documents, best first
+	Rank           int      `json:"rank"`      // Rank of the first expected document, 0 if none was retrieved
+	Hit            bool     `json:"hit"`
+	ReciprocalRank float64  `json:"reciprocal_rank"`
+	Recall         float64  `json:"recall"`
+	Missed         []string `json:"missed,omitempty"`
+}
+
+// evalSummary averages the results of all queries
+type evalSummary struct {
+	Type       string  `json:"type"`
+	Queries    int     `json:"queries"`
+	TopK       int     `json:"top_k"`
+	HitRate    float64 `json:"hit_rate"`
+	MRR        float64 `json:"mrr"`
+	Recall     float64 `json:"recall"`
+	DurationMS int64   `json:"duration_ms"`
+}
+
+var evalCmd = &cobra.Command{
+	Use:   "eval <queries.yaml>",
+	Short: "Evaluate retrieval against a golden query set",
+	Long: `Run each query of a golden query set against the Ragie retrievals API and
+report how well the expected documents are retrieved, to catch recall regressions
+after an import.
+
+The query file is YAML (or JSON) listing each query with the external IDs of the
+documents that should answer it:
+
+  top_k: 8                          # optional, overridden by --top-k
+  filter: {source_type: readmeio}   # optional, applies to every query
+  queries:
+    - query: How do I rotate an API key?
+      expected: [files:docs:api-keys.md]
+    - query: What are the rate limits?
+      expected: [files:docs:limits.md, files:docs:errors.md]
+      filter: {category: reference}  # optional, added to the common filter
+
+Metrics:
+  hit@k    share of queries for which an expected document was retrieved
+  MRR      mean reciprocal rank of the first expected document, ranking the
+           distinct documents of the retrieved chunks
+  recall   mean share of the expected documents that were retrieved
+
+Output:
+  --output text (default) lists each query with its rank and missed documents,
+  json prints all results and the summary once done, and ndjson prints one line
+  per query followed by a summary line.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return err
+		}
+
+		set, err := loadEvalSet(args[0])
+		if err != nil {
+			return err
+		}
+
+		filter, err := parseFilter(evalFilter, evalWhere)
+		if err != nil {
+			return err
+		}
+		for key, value := range filter {
+			if set.Filter == nil {
+				set.Filter = map[string]interface{}{}
+			}
+			set.Filter[key] = value
+		}
+		if cmd.Flags().Changed("top-k") {
+			set.TopK = evalTopK
+		}
+		if evalRerank {
+			set.Rerank = true
+		}
+
+		return runEval(cmd.Context(), newClient(), set, partition, outputFormat)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(evalCmd)
+	evalCmd.Flags().IntVar(&evalTopK, "top-k", defaultEvalTopK, "Number of chunks retrieved per query, overrides the query file")
+	evalCmd.Flags().StringVar(&evalFilter, "filter", "", "Metadata filter as JSON added to every query, e.g. '{\"source_type\": \"files\"}'")
+	evalCmd.Flags().StringArrayVar(&evalWhere, "where", nil, "Metadata equality condition as key=value added to every query (repeatable)")
+	evalCmd.Flags().BoolVar(&evalRerank, "rerank", false, "Rerank the retrieved chunks, as the application being evaluated would")
+}
+
+// loadEvalSet reads and validates a query file
+func loadEvalSet(path string) (*evalSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query file: %v", err)
+	}
+
+	var set evalSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse query file %s: %v", path, err)
+	}
+
+	if len(set.Queries) == 0 {
+		return nil, fmt.Errorf("no queries found in %s", path)
+	}
+	for i, q := range set.Queries {
+		if q.Query == "" {
+			return nil, fmt.Errorf("query %d in %s is empty", i+1, path)
+		}
+		if len(q.Expected) == 0 {
+			return nil, fmt.Errorf("query %d in %s has no expected documents", i+1, path)
+		}
+	}
+
+	return &set, nil
+}
+
+// runEval runs every query of set and writes the results and the summary
+func runEval(ctx context.Context, c *client.Client, set *evalSet, partition string, format string) error {
+	start := time.Now()
+	topK := set.TopK
+	if topK <= 0 {
+		topK = defaultEvalTopK
+	}
+
+	results := make([]evalResult, 0, len(set.Queries))
+	for i, q := range set.Queries {
+		filter := map[string]interface{}{}
+		for key, value := range set.Filter {
+			filter[key] = value
+		}
+		for key, value := range q.Filter {
+			filter[key] = value
+		}
+
+		resp, err := c.RetrieveContext(ctx, q.Query, client.RetrieveOptions{
+			TopK:      topK,
+			Filter:    filter,
+			Rerank:    set.Rerank,
+			Partition: partition,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to run query %d '%s': %w", i+1, q.Query, err)
+		}
+
+		result := scoreQuery(q, resp.ScoredChunks)
+		results = append(results, result)
+		if format == OutputNDJSON {
+			line, _ := json.Marshal(result)
+			fmt.Fprintf(stdout, "%s\n", line)
+		}
+	}
+
+	s := summarizeEval(results, topK)
+	s.DurationMS = since(start)
+	return writeEval(stdout, format, results, s)
+}
+
+// scoreQuery ranks the distinct documents of the retrieved chunks, best first, and
+// compares them to the expected external IDs
+func scoreQuery(q evalQuery, chunks []client.ScoredChunk) evalResult {
+	result := evalResult{
+		Type:      "query",
+		Query:     q.Query,
+		Expected:  q.Expected,
+		Retrieved: []string{},
+	}
+
+	seen := map[string]bool{}
+	for _, chunk := range chunks {
+		externalID, _ := chunk.DocumentMetadata["external_id"].(string)
+		if externalID == "" {
+			externalID = chunk.DocumentID
+		}
+		if !seen[externalID] {
+			seen[externalID] = true
+			result.Retrieved = append(result.Retrieved, externalID)
+		}
+	}
+
+	found := 0
+	for _, expected := range q.Expected {
+		if seen[expected] {
+			found++
+		} else {
+			result.Missed = append(result.Missed, expected)
+		}
+	}
+	result.Recall = float64(found) / float64(len(q.Expected))
+
+	for i, externalID := range result.Retrieved {
+		if !slices.Contains(q.Expected, externalID) {
+			continue
+		}
+		result.Rank = i + 1
+		result.Hit = true
+		result.ReciprocalRank = 1 / float64(result.Rank)
+		break
+	}
+
+	return result
+}
+
+func summarizeEval(results []evalResult, topK int) evalSummary {
+	s := evalSummary{Type: "summary", Queries: len(results), TopK: topK}
+	if len(results) == 0 {
+		return s
+	}
+
+	for _, r := range results {
+		if r.Hit {
+			s.HitRate++
+		}
+		s.MRR += r.ReciprocalRank
+		s.Recall += r.Recall
+	}
+	n := float64(len(results))
+	s.HitRate /= n
+	s.MRR /= n
+	s.Recall /= n
+	return s
+}
+
+// writeEval writes the results in the text and json formats, and the summary line
+// with ndjson, whose results were already streamed
+func writeEval(w io.Writer, format string, results []evalResult, s evalSummary) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(struct {
+			Queries []evalResult `json:"queries"`
+			Summary evalSummary  `json:"summary"`
+		}{results, s}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+
+	case OutputNDJSON:
+		line, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", line)
+		return err
+
+	default:
+		for _, r := range results {
+			if r.Hit {
+				fmt.Fprintf(w, "hit   rank %-3d %s\n", r.Rank, r.Query)
+			} else {
+				fmt.Fprintf(w, "miss  rank -   %s\n", r.Query)
+			}
+			for _, missed := range r.Missed {
+				fmt.Fprintf(w, "      missed: %s\n", missed)
+			}
+		}
+		_, err := fmt.Fprintf(w, "eval: %d queries, hit@%d %.2f, MRR %.2f, recall %.2f in %v\n",
+			s.Queries, s.TopK, s.HitRate, s.MRR, s.Recall, time.Duration(s.DurationMS)*time.Millisecond)
+		return err
+	}
+}
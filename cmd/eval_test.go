@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"ragie/pkg/client"
+)
+
+func TestScoreQuery(t *testing.T) {
+	chunk := func(externalID string) client.ScoredChunk {
+		return client.ScoredChunk{DocumentID: "id-" + externalID, DocumentMetadata: map[string]interface{}{"external_id": externalID}}
+	}
+
+	tests := []struct {
+		name     string
+		expected []string
+		chunks   []client.ScoredChunk
+		result   evalResult
+	}{
+		{
+			name:     "first",
+			expected: []string{"a.md"},
+			chunks:   []client.ScoredChunk{chunk("a.md"), chunk("b.md")},
+			result:   evalResult{Retrieved: []string{"a.md", "b.md"}, Rank: 1, Hit: true, ReciprocalRank: 1, Recall: 1},
+		},
+		{
+			name:     "ranked by distinct documents",
+			expected: []string{"c.md", "d.md"},
+			chunks:   []client.ScoredChunk{chunk("a.md"), chunk("a.md"), chunk("b.md"), chunk("c.md")},
+			result:   evalResult{Retrieved: []string{"a.md", "b.md", "c.md"}, Rank: 3, Hit: true, ReciprocalRank: 1.0 / 3, Recall: 0.5, Missed: []string{"d.md"}},
+		},
+		{
+			name:     "miss",
+			expected: []string{"c.md"},
+			chunks:   []client.ScoredChunk{chunk("a.md")},
+			result:   evalResult{Retrieved: []string{"a.md"}, Missed: []string{"c.md"}},
+		},
+		{
+			name:     "no chunks",
+			expected: []string{"c.md"},
+			result:   evalResult{Retrieved: []string{}, Missed: []string{"c.md"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := evalQuery{Query: "query", Expected: tt.expected}
+			tt.result.Type, tt.result.Query, tt.result.Expected = "query", q.Query, q.Expected
+
+			result := scoreQuery(q, tt.chunks)
+			if !reflect.DeepEqual(result, tt.result) {
+				t.Errorf("Expected %+v, got %+v", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestLoadEvalSet(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError bool
+	}{
+		{name: "yaml", content: "top_k: 4\nqueries:\n  - query: keys\n    expected: [keys.md]\n    filter: {source_type: files}\n"},
+		{name: "json", content: `{"queries": [{"query": "keys", "expected": ["keys.md"]}]}`},
+		{name: "no queries", content: "top_k: 4\n", expectError: true},
+		{name: "no expected documents", content: "queries:\n  - query: keys\n", expectError: true},
+		{name: "empty query", content: "queries:\n  - expected: [keys.md]\n", expectError: true},
+		{name: "invalid", content: "queries: [", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "queries.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write query file: %v", err)
+			}
+
+			set, err := loadEvalSet(path)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if set.Queries[0].Query != "keys" || set.Queries[0].Expected[0] != "keys.md" {
+				t.Errorf("Unexpected queries %+v", set.Queries)
+			}
+		})
+	}
+}
+
+func TestRunEval(t *testing.T) {
+	captureStdout(t)
+	api := newFakeAPI(t)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"keys.md":   "Rotate API keys from the dashboard",
+		"limits.md": "Rate limits apply per API key",
+		"intro.md":  "Welcome",
+	})
+	if err := ImportFiles(context.Background(), api.client(), dir, ImportConfig{}); err != nil {
+		t.Fatalf("ImportFiles returned error: %v", err)
+	}
+
+	set := &evalSet{Queries: []evalQuery{
+		{Query: "rotate keys", Expected: []string{filesID(dir, "keys.md")}},
+		{Query: "rate limits API", Expected: []string{filesID(dir, "limits.md")}},
+		{Query: "welcome", Expected: []string{filesID(dir, "missing.md")}},
+	}}
+
+	out := captureStdout(t)
+	if err := runEval(context.Background(), api.client(), set, "", OutputJSON); err != nil {
+		t.Fatalf("runEval returned error: %v", err)
+	}
+
+	var doc struct {
+		Queries []evalResult `json:"queries"`
+		Summary evalSummary  `json:"summary"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", out.String(), err)
+	}
+
+	if len(doc.Queries) != 3 || !doc.Queries[0].Hit || doc.Queries[2].Hit {
+		t.Errorf("Unexpected results %+v", doc.Queries)
+	}
+	if doc.Summary.Queries != 3 || doc.Summary.TopK != defaultEvalTopK {
+		t.Errorf("Expected 3 queries with top k %d, got %+v", defaultEvalTopK, doc.Summary)
+	}
+	if doc.Summary.HitRate != 2.0/3 || doc.Summary.MRR != 2.0/3 {
+		t.Errorf("Expected hit rate and MRR of 2/3, got %+v", doc.Summary)
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the file at the root of an imported directory or zip archive
+// listing the paths that are not imported, in gitignore syntax
+const IgnoreFileName = ".ragieignore"
+
+// ignorePattern is a single gitignore pattern compiled to a regular expression
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreMatcher matches slash separated paths relative to the import root against
+// patterns in gitignore syntax, where the last matching pattern wins
+type ignoreMatcher struct {
+	patterns []ignorePattern
+}
+
+// readIgnoreFile adds the patterns of a gitignore style file to m
+func (m *ignoreMatcher) readIgnoreFile(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if err := m.Add(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Add adds a pattern. Blank lines and comments are ignored.
+func (m *ignoreMatcher) Add(line string) error {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasSuffix(line, `\ `) {
+		line = strings.TrimRight(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	p := ignorePattern{}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+
+	// A pattern with a slash other than a trailing one is relative to the root,
+	// otherwise it matches at any depth
+	prefix := "^(?:.*/)?"
+	if strings.Contains(line, "/") {
+		prefix = "^"
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	re, err := regexp.Compile(prefix + globRegexp(line) + "$")
+	if err != nil {
+		return fmt.Errorf("invalid pattern '%s': %v", line, err)
+	}
+	p.re = re
+
+	m.patterns = append(m.patterns, p)
+	return nil
+}
+
+// Match reports whether the path itself matches, without checking its parents
+func (m *ignoreMatcher) Match(relPath string, isDir bool) bool {
+	matched := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(relPath) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+// MatchPath reports whether the path or any of its parent directories match
+func (m *ignoreMatcher) MatchPath(relPath string, isDir bool) bool {
+	for dir := path.Dir(relPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if m.Match(dir, true) {
+			return true
+		}
+	}
+	return m.Match(relPath, isDir)
+}
+
+// globRegexp translates a glob with *, ?, [...] and ** to a regular expression
+func globRegexp(glob string) string {
+	var re strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// Zero or more directories
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			re.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return re.String()
+}
+
+// importFilter decides which files of a directory or zip archive are imported,
+// from the --include and --exclude globs and the .ragieignore file at the root
+type importFilter struct {
+	include *ignoreMatcher
+	exclude *ignoreMatcher
+}
+
+// newImportFilter returns a filter, or nil if nothing is filtered. ignoreFile is the
+// content of the .ragieignore file, if there is one.
+func newImportFilter(include []string, exclude []string, ignoreFile io.Reader) (*importFilter, error) {
+	f := &importFilter{exclude: &ignoreMatcher{}}
+
+	if ignoreFile != nil {
+		if err := f.exclude.readIgnoreFile(ignoreFile); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", IgnoreFileName, err)
+		}
+	}
+
+	// The flags come last so that they take precedence over the ignore file
+	for _, pattern := range exclude {
+		if err := f.exclude.Add(pattern); err != nil {
+			return nil, fmt.Errorf("invalid --exclude: %v", err)
+		}
+	}
+
+	if len(include) > 0 {
+		f.include = &ignoreMatcher{}
+		for _, pattern := range include {
+			if err := f.include.Add(pattern); err != nil {
+				return nil, fmt.Errorf("invalid --include: %v", err)
+			}
+		}
+	}
+
+	if f.include == nil && len(f.exclude.patterns) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// Excluded reports whether the file or directory at relPath is not imported. The
+// include globs only apply to files, so that directories are always searched.
+func (f *importFilter) Excluded(relPath string, isDir bool) bool {
+	if f == nil {
+		return false
+	}
+	if f.exclude.MatchPath(relPath, isDir) {
+		return true
+	}
+	return !isDir && f.include != nil && !f.include.Match(relPath, false)
+}
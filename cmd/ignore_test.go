@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	ignoreFile := `
+# dependencies
+node_modules/
+*.lock
+/build
+docs/**/draft-*.md
+!keep.lock
+\#notes.txt
+logs/**
+`
+
+	m := &ignoreMatcher{}
+	if err := m.readIgnoreFile(strings.NewReader(ignoreFile)); err != nil {
+		t.Fatalf("Failed to read ignore file: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{path: "node_modules", isDir: true, expected: true},
+		{path: "web/node_modules", isDir: true, expected: true},
+		{path: "web/node_modules/react/index.js", isDir: false, expected: true},
+		{path: "node_modules", isDir: false, expected: false},
+		{path: "yarn.lock", isDir: false, expected: true},
+		{path: "sub/Cargo.lock", isDir: false, expected: true},
+		{path: "keep.lock", isDir: false, expected: false},
+		{path: "build", isDir: true, expected: true},
+		{path: "build/out.txt", isDir: false, expected: true},
+		{path: "src/build/out.txt", isDir: false, expected: false},
+		{path: "docs/draft-a.md", isDir: false, expected: true},
+		{path: "docs/guides/v1/draft-b.md", isDir: false, expected: true},
+		{path: "docs/guides/final.md", isDir: false, expected: false},
+		{path: "#notes.txt", isDir: false, expected: true},
+		{path: "logs/2024/app.log", isDir: false, expected: true},
+		{path: "logs", isDir: true, expected: false},
+		{path: "README.md", isDir: false, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.MatchPath(tt.path, tt.isDir); got != tt.expected {
+				t.Errorf("Expected match=%v for %s, got %v", tt.expected, tt.path, got)
+			}
+		})
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob     string
+		expected string
+	}{
+		{glob: "*.md", expected: `[^/]*\.md`},
+		{glob: "file?.txt", expected: `file[^/]\.txt`},
+		{glob: "[!a-c]x", expected: `[^a-c]x`},
+		{glob: "**/a", expected: `(?:.*/)?a`},
+		{glob: "a/**/b", expected: `a/(?:.*/)?b`},
+		{glob: "a/**", expected: `a/.*`},
+		{glob: `\*`, expected: `\*`},
+		{glob: "[abc", expected: `\[abc`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			if got := globRegexp(tt.glob); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestImportFilter(t *testing.T) {
+	filter, err := newImportFilter([]string{"*.md", "docs/**"}, []string{"docs/private/", "!README.md"}, strings.NewReader("README.md\n*.tmp\n"))
+	if err != nil {
+		t.Fatalf("Failed to create filter: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{path: "README.md", isDir: false, expected: false},
+		{path: "guide.md", isDir: false, expected: false},
+		{path: "main.go", isDir: false, expected: true},
+		{path: "src", isDir: true, expected: false},
+		{path: "docs/api.json", isDir: false, expected: false},
+		{path: "docs/cache.tmp", isDir: false, expected: true},
+		{path: "docs/private", isDir: true, expected: true},
+		{path: "docs/private/keys.md", isDir: false, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := filter.Excluded(tt.path, tt.isDir); got != tt.expected {
+				t.Errorf("Expected excluded=%v for %s, got %v", tt.expected, tt.path, got)
+			}
+		})
+	}
+
+	if filter, err := newImportFilter(nil, nil, nil); err != nil || filter != nil {
+		t.Errorf("Expected no filter without patterns, got %v, %v", filter, err)
+	}
+	if _, err := newImportFilter(nil, []string{"[z-a]"}, nil); err == nil {
+		t.Errorf("Expected error for invalid pattern")
+	}
+}
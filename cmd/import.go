@@ -1,69 +1,138 @@
 package cmd
 
 import (
-	"archive/zip"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"ragie/pkg/client"
 
-	"github.com/beevik/etree"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // ImportConfig holds configuration for import operations
 type ImportConfig struct {
-	DryRun    bool
-	Delay     float64
-	Partition string
-	Mode      string
-	Force     bool
-	Replace   bool
+	DryRun      bool
+	Delay       float64 // Deprecated: minimum seconds between items, prefer a client rate limit
+	Partition   string
+	Mode        string
+	Force       bool
+	Replace     bool
+	Concurrency int
+	StatePath   string // Checkpoint file recording imported documents, disabled when empty
+	Resume      bool   // Skip documents recorded in the checkpoint by a previous run
+	Sync        bool   // Replace existing documents only when their content hash changed
+	Update      bool   // Update existing documents in place, keeping their document ID
+	Prune       bool   // Delete documents whose source file no longer exists (files and zip only)
+	Output      string // Output format: text (default), json or ndjson
+	FailFast    bool   // Abort the import on the first failed item
+	Wait        bool   // Poll the created documents until they are ready or failed
+
+	WaitTimeout time.Duration // How long to wait for documents to be processed, 0 waits forever
+
+	// Only update the metadata of existing documents whose content hash matches, rather
+	// than uploading them again
+	UpdateMetadata bool
+
+	// Globs of the files to import and to leave out, in gitignore syntax (files and zip only)
+	Include []string
+	Exclude []string
+
+	// Post types and statuses of the items to import from a WordPress export (wordpress only)
+	PostTypes []string
+	Statuses  []string
+
+	// Namespace of the external IDs, which are IDPrefix:<ID in the source>. The import
+	// type's own namespace is used if empty, e.g. "files:<directory name>".
+	IDPrefix string
+}
+
+// Validate checks that the conflict handling options are not combined
+func (config ImportConfig) Validate() error {
+	// Validate that --force and --replace are mutually exclusive
+	if config.Force && config.Replace {
+		return fmt.Errorf("--force and --replace flags cannot be used together")
+	}
+	if config.Sync && (config.Force || config.Replace) {
+		return fmt.Errorf("--sync cannot be used together with --force or --replace")
+	}
+	if config.Update && (config.Force || config.Replace) {
+		return fmt.Errorf("--update cannot be used together with --force or --replace")
+	}
+	if config.UpdateMetadata && config.Force {
+		return fmt.Errorf("--update-metadata cannot be used together with --force")
+	}
+	return validateOutputFormat(config.Output)
 }
 
 var importCmd = &cobra.Command{
-	Use:   "import [type] [file]",
+	Use:   "import",
 	Short: "Import data from various sources",
 	Long: `Import data from various sources into Ragie.
 
-Available import types:
-
-  youtube
-    Imports YouTube video transcripts and metadata from a JSON file.
-    The JSON file should contain an array of objects with videoId, title, and captions fields.
-    Each video will be imported as a separate document with its transcript and metadata.
-    Example: ragie import youtube path/to/youtube_videos.json
-
-  wordpress
-    Imports WordPress content from an XML export file (WXR format).
-    Imports posts, pages, and their metadata including titles, descriptions, and content.
-    Each post/page will be imported as a separate document.
-    Example: ragie import wordpress path/to/wordpress-export.xml
-
-  readmeio
-    Imports ReadmeIO documentation from a ZIP archive.
-    The ZIP should contain Markdown files with YAML frontmatter.
-    Each Markdown file will be imported as a separate document, preserving metadata.
-    Example: ragie import readmeio path/to/readme-docs.zip
-
-  files
-    Imports files from a directory recursively or a file.
-    All non-empty files will be imported as separate documents.
-    Preserves file metadata including path, extension, size, and modification time.
-    Example: ragie import files path/to/documents/
-    Example: ragie import files path/to/file.txt
-
-  zip
-    Imports all files from a zip archive without extracting them.
-    Each file will be imported as a separate document.
-    Preserves file metadata including path, extension, size, and modification time.
-    Example: ragie import zip path/to/documents.zip
+Each import type is a subcommand with its own help, e.g. 'ragie import files
+--help'. All import types share the options below.
+
+Existing documents:
+  Before importing, all documents of the partition are listed once to find the
+  documents that already exist with each external ID. These are skipped, unless
+  --force, --replace, --update or --sync is given. The import is aborted if the documents
+  cannot be listed, rather than uploading duplicates.
+
+Replacing:
+  With --replace, or --sync for changed content, the new version is uploaded
+  before all earlier documents with its external ID are deleted, so the content
+  stays available. If they cannot be deleted, the new document is deleted
+  instead. With --wait, the earlier documents are deleted once the new one is
+  ready, and kept if its processing fails.
+
+Updating:
+  With --update, the content of an existing document is replaced in place and
+  the import's metadata is merged into its metadata, so the document keeps its
+  ID. Combined with --sync, only documents whose content changed are updated.
+
+  With --update-metadata, existing documents whose content is unchanged only get
+  the metadata that differs updated, without being uploaded and processed again,
+  e.g. after adding a metadata field. Other documents follow the other flags.
+
+External IDs:
+  The external ID of each document is namespaced by its source, so that
+  documents of different sources never collide:
+    files      files:<directory name>:<path>, a single file as in its directory
+    zip        zip:<archive name>:<path>
+    youtube    youtube:<videoId>
+    readmeio   readmeio:<slug>
+    wordpress  wordpress:<guid>
+  --id-prefix replaces the namespace, e.g. --id-prefix handbook imports
+  docs/intro.md as handbook:docs/intro.md. Documents imported by earlier versions
+  have bare external IDs, rewrite them with 'ragie migrate external-ids' before
+  importing again.
+
+Syncing:
+  The SHA-256 hash of each document's content is stored in its content_hash
+  metadata. With --sync, existing documents whose hash is unchanged are left
+  alone, changed documents are replaced and new documents are created. A count
+  of new, changed and unchanged documents is printed at the end.
+
+Waiting:
+  Documents are processed by Ragie after they are uploaded. With --wait, once
+  all documents are uploaded the import polls each created document until it is
+  ready or its processing failed. Documents that failed, or are still processing
+  after --wait-timeout, are reported as failed processing and the import exits
+  with status 2.
+
+Resuming:
+  With --resume or --state, every imported document is recorded in a checkpoint
+  file (--state, default .ragie-import-state.jsonl). If an import is interrupted,
+  rerun the same command with --resume to skip the documents that were already
+  imported with the same content. Existing documents are still listed once, but
+  skipped documents are not looked up or uploaded again. A checkpoint of another
+  source or partition is never overwritten, use a different --state for each
+  import.
 
 Options:
   --mode string    Processing mode: 'hi_res' (high resolution), 'fast' (default), or 'all'
@@ -71,545 +140,446 @@ Options:
                    fast: Faster processing with slightly lower accuracy
                    all: Highest quality processing for all media types
                    Note: mode is only supported for 'files' and 'zip' import types`,
-	Args: cobra.ExactArgs(2),
+	// Import types are subcommands, so any argument left is an unknown type
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		importType := args[0]
-		file := args[1]
-
-		// Validate that --force and --replace are mutually exclusive
-		if force && replace {
-			return fmt.Errorf("--force and --replace flags cannot be used together")
-		}
-
-		ragieClient := client.NewClient(viper.GetString("api_key"))
-		config := ImportConfig{
-			DryRun:    dryRun,
-			Delay:     delay,
-			Partition: partition,
-			Mode:      mode,
-			Force:     force,
-			Replace:   replace,
-		}
-
-		switch importType {
-		case "youtube":
-			return ImportYouTube(ragieClient, file, config)
-		case "wordpress":
-			return ImportWordPress(ragieClient, file, config)
-		case "readmeio":
-			return ImportReadmeIO(ragieClient, file, config)
-		case "files":
-			return ImportFiles(ragieClient, file, config)
-		case "zip":
-			return ImportZip(ragieClient, file, config)
-		default:
-			return fmt.Errorf("unknown import type: %s", importType)
-		}
+		return cmd.Help()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(importCmd)
-	importCmd.Flags().StringVar(&mode, "mode", "", "Processing mode: 'hi_res' (high resolution), 'fast' (default), or 'all' (highest quality). Only supported for 'files' and 'zip' import types (file upload API).")
-	importCmd.Flags().BoolVar(&force, "force", false, "Force import even if documents with the same external ID already exist (creates a new document with the same external ID)")
-	importCmd.Flags().BoolVar(&replace, "replace", false, "Replace existing documents with the same external ID (deletes the existing document and creates a new one)")
+	flags := importCmd.PersistentFlags()
+	flags.StringVar(&mode, "mode", "", "Processing mode: 'hi_res' (high resolution), 'fast' (default), or 'all' (highest quality). Only supported for 'files' and 'zip' import types (file upload API).")
+	flags.BoolVar(&force, "force", false, "Force import even if documents with the same external ID already exist (creates a new document with the same external ID)")
+	flags.BoolVar(&replace, "replace", false, "Replace existing documents with the same external ID (uploads the new version, then deletes the existing documents)")
+	flags.BoolVar(&syncMode, "sync", false, "Only upload new documents and documents whose content hash changed, replacing the outdated version")
+	flags.BoolVar(&updateMode, "update", false, "Update the content and metadata of existing documents in place, keeping their document ID (with --sync, only when the content changed)")
+	flags.BoolVar(&updateMeta, "update-metadata", false, "Only update the metadata of existing documents whose content is unchanged, instead of uploading them again")
+	flags.BoolVar(&wait, "wait", false, "Wait until the created documents are processed, and report the documents that failed processing")
+	flags.DurationVar(&waitTimeout, "wait-timeout", 30*time.Minute, "How long --wait waits for documents to be processed before reporting them as failed (0 waits forever)")
+	flags.StringVar(&idPrefix, "id-prefix", "", "Namespace of the external IDs instead of the import type's, e.g. 'handbook' for handbook:<path>")
+	flags.StringVar(&statePath, "state", "", "Checkpoint file recording imported documents, used by --resume (default "+DefaultStatePath+" with --resume)")
+	flags.BoolVar(&resume, "resume", false, "Resume an interrupted import: documents recorded in the checkpoint with the same content are not looked up or uploaded again (existing documents are still listed)")
 }
 
-func documentExists(c *client.Client, config ImportConfig, externalID string) bool {
-	opts := client.ListOptions{
-		Filter:    map[string]interface{}{"external_id": externalID},
-		PageSize:  1,
-		Partition: config.Partition,
+// importConfigFromFlags returns the configuration of an import from the global flags
+func importConfigFromFlags() ImportConfig {
+	return ImportConfig{
+		DryRun:      dryRun,
+		Partition:   partition,
+		Mode:        mode,
+		Force:       force,
+		Replace:     replace,
+		Concurrency: concurrency,
+		StatePath:   importStatePath(),
+		Resume:      resume,
+		Sync:        syncMode,
+		Update:      updateMode,
+		Prune:       prune,
+		Output:      outputFormat,
+		FailFast:    failFast,
+		Wait:        wait,
+		WaitTimeout: waitTimeout,
+		Include:     includes,
+		Exclude:     excludes,
+		PostTypes:   postTypes,
+		Statuses:    postStatuses,
+		IDPrefix:    idPrefix,
+
+		UpdateMetadata: updateMeta,
 	}
+}
 
-	resp, err := c.ListDocuments(opts)
-	if err != nil {
-		return false
+// importStatePath returns the checkpoint file of an import. Only imports that may be
+// resumed write one, so that unrelated imports do not overwrite each other's.
+func importStatePath() string {
+	if statePath == "" && resume {
+		return DefaultStatePath
 	}
-	return len(resp.Documents) > 0
+	return statePath
 }
 
-// replaceExistingDocuments deletes all existing documents with the given external ID
-func replaceExistingDocuments(c *client.Client, config ImportConfig, externalID string) error {
+// removePriorDocuments deletes the documents with the given external ID other than
+// the new document keepID, across all pages, and returns the IDs of the deleted documents
+func (r *importRun) removePriorDocuments(ctx context.Context, externalID string, keepID string, out io.Writer) ([]string, error) {
 	opts := client.ListOptions{
 		Filter:    map[string]interface{}{"external_id": externalID},
-		PageSize:  100, // Get all documents with this external_id
-		Partition: config.Partition,
+		Partition: r.config.Partition,
 	}
-
-	resp, err := c.ListDocuments(opts)
+	docs, err := listDocuments(ctx, r.client, opts, 0)
 	if err != nil {
-		return fmt.Errorf("failed to list existing documents: %v", err)
+		return nil, fmt.Errorf("failed to list existing documents: %w", err)
 	}
 
-	for _, doc := range resp.Documents {
-		if config.DryRun {
-			fmt.Printf("would delete existing document: %s\n", doc.ID)
-		} else {
-			err := c.DeleteDocument(doc.ID)
-			if err != nil {
-				return fmt.Errorf("failed to delete existing document %s: %v", doc.ID, err)
-			}
-			fmt.Printf("deleted existing document: %s\n", doc.ID)
+	var deleted []string
+	for _, doc := range docs {
+		if doc.ID == keepID {
+			continue
+		}
+		err := r.client.DeleteDocumentContext(ctx, doc.ID)
+		if err != nil && !errors.Is(err, client.ErrNotFound) {
+			return deleted, fmt.Errorf("failed to delete existing document %s: %w", doc.ID, err)
 		}
+		r.index.Remove(externalID, doc.ID)
+		r.report.Textf(out, "deleted existing document: %s\n", doc.ID)
+		deleted = append(deleted, doc.ID)
 	}
 
-	return nil
+	return deleted, nil
 }
 
-func createDocumentRaw(c *client.Client, externalID string, name, data string, metadata map[string]interface{}, config ImportConfig) error {
-	if config.DryRun {
-		fmt.Printf("would save document: %s\n", name)
-		return nil
-	}
-
-	metadata["external_id"] = externalID
-
-	doc, err := c.CreateDocumentRaw(config.Partition, name, data, metadata)
-	if err != nil {
-		return err
+// rollbackReplace deletes the new document of a replacement whose prior documents
+// could not be removed, so that only one version of the content is kept
+func (r *importRun) rollbackReplace(ctx context.Context, externalID string, docID string) error {
+	err := r.client.DeleteDocumentContext(ctx, docID)
+	if err != nil && !errors.Is(err, client.ErrNotFound) {
+		return fmt.Errorf("failed to delete new document %s: %w", docID, err)
 	}
-
-	fmt.Printf("saved: %s\n", doc.ID)
+	r.index.Remove(externalID, docID)
 	return nil
 }
 
-// createDocument uploads a file using multipart form data
-func createDocument(c *client.Client, externalID string, name string, fileData []byte, fileName string, metadata map[string]interface{}, config ImportConfig) error {
-	if config.DryRun {
-		fmt.Printf("would save document: %s\n", name)
-		return nil
-	}
-
-	metadata["external_id"] = externalID
-
-	doc, err := c.CreateDocument(config.Partition, name, fileData, fileName, metadata, config.Mode)
-	if err != nil {
-		return err
-	}
-
-	fmt.Printf("saved: %s\n", doc.ID)
-	return nil
+// isFatalImportError reports whether err would make every remaining item fail as well,
+// such as an invalid API key, in which case the import is aborted
+func isFatalImportError(err error) bool {
+	return errors.Is(err, client.ErrUnauthorized)
 }
 
-// ImportYouTube imports YouTube data from a JSON file
-func ImportYouTube(c *client.Client, youtubeFile string, config ImportConfig) error {
-	fmt.Printf("Loading YouTube JSON file: %s\n", youtubeFile)
+// importRun holds what is shared by all items of a single import
+type importRun struct {
+	*importPool
+	client *client.Client
+	config ImportConfig
+	state  *importState
+	report *reporter
+	index  *documentIndex // Existing documents of the partition by external ID
+
+	// Paths found in the source and the documents to check against them, used by --prune
+	seenMu      sync.Mutex
+	seen        map[string]bool
+	pruneFilter map[string]interface{}
+
+	// Created documents to poll with --wait, and those that replace existing documents
+	// once they are ready
+	processingMu sync.Mutex
+	processing   []event
+	replacing    map[string]bool
+}
 
-	data, err := os.ReadFile(youtubeFile)
+func newImportRun(ctx context.Context, c *client.Client, config ImportConfig) (*importRun, error) {
+	report, err := newReporter(config.Output, "import", config.DryRun)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %v", err)
+		return nil, err
 	}
 
-	var items []map[string]interface{}
-	if err := json.Unmarshal(data, &items); err != nil {
-		return fmt.Errorf("failed to parse JSON: %v", err)
+	// Existing documents are listed once, rather than for each item
+	index, err := loadDocumentIndex(ctx, c, config.Partition)
+	if err != nil {
+		return nil, err
 	}
+	report.Infof("Found %d existing documents\n", index.Len())
 
-	for _, item := range items {
-		videoID, ok := item["videoId"].(string)
-		if !ok || videoID == "" {
-			fmt.Println("warning: skipping item with no videoId")
-			continue
-		}
-
-		// Handle existing documents based on flags
-		docExists := documentExists(c, config, videoID)
-		if docExists && !config.Force && !config.Replace {
-			fmt.Printf("warning: skipping video with existing document: %s\n", videoID)
-			continue
-		}
-
-		// Replace existing documents if --replace flag is used
-		if config.Replace && docExists {
-			err := replaceExistingDocuments(c, config, videoID)
-			if err != nil {
-				fmt.Printf("failed to replace existing documents for video %s: %v\n", videoID, err)
-				continue
-			}
-		}
-
-		title, _ := item["title"].(string)
-		captions, _ := item["captions"].([]interface{})
-
-		var content strings.Builder
-		if title != "" {
-			content.WriteString(title)
-			content.WriteString("\n\n")
-		}
-
-		for _, cap := range captions {
-			if str, ok := cap.(string); ok && str != "" {
-				content.WriteString(str)
-				content.WriteString("\n")
-			}
-		}
-
-		if content.Len() == 0 {
-			fmt.Printf("warning: refusing to upload empty content: %s\n", videoID)
-			continue
-		}
-
-		err := createDocumentRaw(c, videoID, title, content.String(), map[string]interface{}{
-			"title": title,
-		}, config)
-		if err != nil {
-			fmt.Printf("failed to import video %s: %v\n", videoID, err)
-		}
-
-		if config.Delay > 0 {
-			time.Sleep(time.Duration(config.Delay * float64(time.Second)))
-		}
+	state, err := openImportState(config)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &importRun{
+		importPool: newImportPool(ctx, config),
+		client:     c,
+		config:     config,
+		state:      state,
+		report:     report,
+		index:      index,
+	}, nil
 }
 
-// ImportWordPress imports WordPress data from an XML file
-func ImportWordPress(c *client.Client, wordpressFile string, config ImportConfig) error {
-	fmt.Printf("Loading WordPress XML file: %s\n", wordpressFile)
-
-	doc := etree.NewDocument()
-	if err := doc.ReadFromFile(wordpressFile); err != nil {
-		return fmt.Errorf("failed to read XML file: %v", err)
+// Wait waits for all items, closes the checkpoint, waits for the created documents to
+// be processed and prunes if requested, and prints the sync counts and the summary. It returns an *ItemsFailedError if any item failed.
+func (r *importRun) Wait() error {
+	err := r.importPool.Wait()
+	if closeErr := r.state.Close(); err == nil {
+		err = closeErr
 	}
 
-	root := doc.Root()
-	if root == nil {
-		return fmt.Errorf("empty XML file")
+	if err == nil && r.config.Wait {
+		err = r.waitProcessed(r.parent)
 	}
 
-	for _, item := range root.FindElements(".//post") {
-		metadata := map[string]interface{}{
-			"sourceType": "wordpress",
-		}
-
-		urlElem := item.FindElement("url")
-		url := ""
-		if urlElem != nil {
-			url = urlElem.Text()
-		}
-		metadata["url"] = url
-
-		// Handle existing documents based on flags
-		docExists := documentExists(c, config, url)
-		if docExists && !config.Force && !config.Replace {
-			fmt.Printf("warning: skipping post with existing document: %s\n", url)
-			continue
-		}
-
-		// Replace existing documents if --replace flag is used
-		if config.Replace && docExists {
-			err := replaceExistingDocuments(c, config, url)
-			if err != nil {
-				fmt.Printf("failed to replace existing documents for post %s: %v\n", url, err)
-				continue
-			}
-		}
-
-		titleElem := item.FindElement("title")
-		title := ""
-		if titleElem != nil {
-			title = titleElem.Text()
-		}
-		metadata["title"] = title
-
-		descElem := item.FindElement("description")
-		desc := ""
-		if descElem != nil {
-			desc = descElem.Text()
+	// Pruning after an incomplete import would delete the documents it did not reach,
+	// such as those of a directory that could not be read
+	if err == nil && r.pruneFilter != nil {
+		if failed := r.report.Count(actionFailed); failed > 0 {
+			r.report.Infof("Skipping prune, as %d items failed\n", failed)
+		} else {
+			err = r.prune(r.parent, r.pruneFilter)
 		}
+	}
 
-		contentElem := item.FindElement("content")
-		content := ""
-		if contentElem != nil {
-			content = contentElem.Text()
-		}
+	if r.config.Sync {
+		r.report.Textf(stdout, "sync: %d new, %d changed, %d unchanged\n",
+			r.report.Count(actionCreated), r.report.Count(actionReplaced)+r.report.Count(actionUpdated), r.report.Count(actionUnchanged))
+	}
 
-		data := strings.Join([]string{title, desc, content}, "\n\n")
+	return r.report.Finish(err)
+}
 
-		err := createDocumentRaw(c, url, title, data, metadata, config)
-		if err != nil {
-			fmt.Printf("failed to import post: %v\n", err)
-		}
+// failItem reports a failed item. The returned error aborts the import with --fail-fast.
+func (r *importRun) failItem(out io.Writer, e event, err error, format string, args ...interface{}) error {
+	e.Action = actionFailed
+	e.Error = err.Error()
+	r.report.Item(out, e, format, args...)
 
-		if config.Delay > 0 {
-			time.Sleep(time.Duration(config.Delay * float64(time.Second)))
-		}
+	if r.config.FailFast {
+		return errFailFast
 	}
-
 	return nil
 }
 
-// ImportReadmeIO imports ReadmeIO data from a ZIP file
-func ImportReadmeIO(c *client.Client, readmeZip string, config ImportConfig) error {
-	fmt.Printf("Loading readme.io ZIP file: %s\n", readmeZip)
-
-	reader, err := zip.OpenReader(readmeZip)
-	if err != nil {
-		return fmt.Errorf("failed to open ZIP file: %v", err)
+// importItem applies the resume, skip, force and replace rules to item, uploads it and
+// reports the outcome
+func (r *importRun) importItem(ctx context.Context, item Record, out io.Writer) error {
+	start := time.Now()
+	e := event{ExternalID: item.ExternalID, Name: item.Name}
+	report := func(action string, format string, args ...interface{}) {
+		e.Action = action
+		e.DurationMS = since(start)
+		r.report.Item(out, e, format, args...)
 	}
-	defer reader.Close()
-
-	for _, file := range reader.File {
-		if !strings.HasSuffix(file.Name, ".md") {
-			continue
-		}
 
-		rc, err := file.Open()
+	hash := item.ContentHash
+	if hash == "" {
+		var blank bool
+		var err error
+		hash, blank, err = recordContentHash(item)
 		if err != nil {
-			fmt.Printf("failed to open file in zip %s: %v\n", file.Name, err)
-			continue
-		}
-
-		content, err := io.ReadAll(rc)
-		rc.Close()
-		if err != nil {
-			fmt.Printf("failed to read file in zip %s: %v\n", file.Name, err)
-			continue
+			e.DurationMS = since(start)
+			return r.failItem(out, e, err, "failed to read %s: %v\n", recordLabel(item), err)
 		}
-
-		contentStr := string(content)
-		if strings.TrimSpace(contentStr) == "" {
-			fmt.Printf("warning: refusing to upload empty content: %s\n", file.Name)
-			continue
+		if blank {
+			e.Message = "empty content"
+			report(actionSkipped, "warning: skipping %s with empty content\n", recordLabel(item))
+			return nil
 		}
+	}
 
-		metadata := map[string]interface{}{
-			"sourceType": "readmeio",
+	if r.config.Resume {
+		if entry, ok := r.state.Completed(r.config.Partition, item.ExternalID, hash); ok {
+			e.DocumentID = entry.DocumentID
+			e.Message = "already imported"
+			report(actionSkipped, "skipping %s already imported as %s: %s\n", item.Kind, entry.DocumentID, item.ExternalID)
+			return nil
 		}
+	}
 
-		// Parse frontmatter
-		parts := strings.SplitN(contentStr, "---", 3)
-		if len(parts) >= 3 {
-			frontmatter := parts[1]
-			contentStr = parts[2]
-
-			for _, line := range strings.Split(frontmatter, "\n") {
-				if strings.Contains(line, ":") {
-					parts := strings.SplitN(line, ":", 2)
-					key := strings.TrimSpace(parts[0])
-					value := strings.Trim(strings.TrimSpace(parts[1]), "\"")
-					metadata[key] = value
-				}
-			}
-		}
+	// Handle existing documents based on flags
+	existing := r.index.Lookup(item.ExternalID)
+	docExists := len(existing) > 0
+	replaceExisting := r.config.Replace && docExists
+	updateExisting := r.config.Update && docExists
+
+	// With --update-metadata, documents whose content is unchanged are not uploaded
+	// again, whatever the other flags
+	if r.config.UpdateMetadata && docExists && existing[0].Metadata["content_hash"] == hash {
+		return r.updateItemMetadata(ctx, item, existing[0], hash, e, start, out)
+	}
 
-		docID, _ := metadata["slug"].(string)
-		if docID == "" {
-			fmt.Printf("warning: skipping document without slug: %s\n", file.Name)
-			continue
+	// With --sync, only documents whose content changed are uploaded again
+	if r.config.Sync && docExists {
+		if existing[0].Metadata["content_hash"] == hash {
+			e.DocumentID = existing[0].ID
+			report(actionUnchanged, "unchanged %s: %s\n", item.Kind, item.ExternalID)
+			return nil
 		}
+		replaceExisting = !r.config.Update
+	} else if docExists && !r.config.Force && !r.config.Replace && !r.config.Update {
+		e.DocumentID = existing[0].ID
+		e.Message = "existing document"
+		report(actionSkipped, "warning: skipping %s with existing document: %s\n", item.Kind, item.ExternalID)
+		return nil
+	}
 
-		metadata["readmeId"] = docID
-
-		// Handle existing documents based on flags
-		docExists := documentExists(c, config, docID)
-		if docExists && !config.Force && !config.Replace {
-			fmt.Printf("warning: skipping document with existing id: %s\n", docID)
-			continue
-		}
+	item.Metadata["content_hash"] = hash
 
-		// Replace existing documents if --replace flag is used
-		if config.Replace && docExists {
-			err := replaceExistingDocuments(c, config, docID)
-			if err != nil {
-				fmt.Printf("failed to replace existing documents for readme document %s: %v\n", docID, err)
-				continue
+	// With --update, the existing document keeps its ID
+	if updateExisting {
+		e.DocumentID = existing[0].ID
+		if err := r.updateDocument(ctx, existing[0].ID, item); err != nil {
+			e.Error = err.Error()
+			if isFatalImportError(err) {
+				report(actionFailed, "")
+				return fmt.Errorf("failed to update %s %s: %w", item.Kind, item.ExternalID, err)
 			}
+			e.DurationMS = since(start)
+			return r.failItem(out, e, err, "failed to update %s %s: %v\n", item.Kind, item.ExternalID, err)
 		}
 
-		title, _ := metadata["title"].(string)
-		if title == "" {
-			title = strings.TrimSuffix(filepath.Base(file.Name), ".md")
-		}
-
-		err = createDocumentRaw(c, docID, title, contentStr, metadata, config)
-		if err != nil {
-			fmt.Printf("failed to import readme document %s: %v\n", file.Name, err)
-		}
-
-		if config.Delay > 0 {
-			time.Sleep(time.Duration(config.Delay * float64(time.Second)))
+		if r.config.DryRun {
+			report(actionUpdated, "would update document %s: %s\n", existing[0].ID, item.Name)
+			return nil
 		}
+		report(actionUpdated, "updated: %s\n", existing[0].ID)
+		r.trackProcessing(e, false)
+		return r.state.Record(r.config.Partition, item.ExternalID, hash, existing[0].ID)
 	}
 
-	return nil
-}
-
-// ImportFiles imports a file or all files from a directory recursively
-func ImportFiles(c *client.Client, path string, config ImportConfig) error {
-	// Check if path exists
-	info, err := os.Stat(path)
-	if err != nil {
-		return fmt.Errorf("failed to access path: %v", err)
+	// The new version is uploaded before the existing documents are deleted, so the
+	// content stays available for retrieval while it is replaced
+	action := actionCreated
+	if replaceExisting {
+		action = actionReplaced
 	}
 
-	// Handle file case
-	if !info.IsDir() {
-		fmt.Printf("Loading file: %s\n", path)
-		return importFile(c, path, path, info, config)
+	doc, err := r.createDocument(ctx, item)
+	if err != nil {
+		e.Error = err.Error()
+		if isFatalImportError(err) {
+			report(actionFailed, "")
+			return fmt.Errorf("failed to import %s %s: %w", item.Kind, item.ExternalID, err)
+		}
+		e.DurationMS = since(start)
+		return r.failItem(out, e, err, "failed to import %s %s: %v\n", item.Kind, item.ExternalID, err)
 	}
 
-	// Handle directory case
-	fmt.Printf("Loading files from directory: %s\n", path)
-
-	// Walk through the directory recursively
-	return filepath.Walk(path, func(filePath string, fileInfo os.FileInfo, err error) error {
-		if err != nil {
-			fmt.Printf("error accessing path %s: %v\n", filePath, err)
-			return nil
+	if doc == nil {
+		if replaceExisting {
+			for _, prior := range existing {
+				r.report.Textf(out, "would delete existing document: %s\n", prior.ID)
+				e.ReplacedDocumentIDs = append(e.ReplacedDocumentIDs, prior.ID)
+			}
 		}
+		report(action, "would save document: %s\n", item.Name)
+		return nil
+	}
 
-		// Skip directories
-		if fileInfo.IsDir() {
-			return nil
-		}
+	if doc.Metadata == nil {
+		doc.Metadata = item.Metadata
+	}
+	r.index.Add(item.ExternalID, *doc)
+	e.DocumentID = doc.ID
 
-		// Process the file
-		relPath, err := filepath.Rel(path, filePath)
+	// With --wait, the existing documents are deleted once the new one is ready
+	if replaceExisting && !r.config.Wait {
+		replaced, err := r.removePriorDocuments(ctx, item.ExternalID, doc.ID, out)
+		e.ReplacedDocumentIDs = replaced
 		if err != nil {
-			fmt.Printf("error getting relative path for %s: %v\n", filePath, err)
-			return nil
+			if rollbackErr := r.rollbackReplace(ctx, item.ExternalID, doc.ID); rollbackErr != nil {
+				err = fmt.Errorf("%w, and %v", err, rollbackErr)
+			}
+			e.DocumentID = ""
+			e.Error = err.Error()
+			if isFatalImportError(err) {
+				report(actionFailed, "")
+				return fmt.Errorf("failed to replace existing documents for %s %s: %w", item.Kind, item.ExternalID, err)
+			}
+			e.DurationMS = since(start)
+			return r.failItem(out, e, err, "failed to replace existing documents for %s %s: %v\n", item.Kind, item.ExternalID, err)
 		}
+	}
 
-		return importFile(c, filePath, relPath, fileInfo, config)
-	})
+	report(action, "saved: %s\n", doc.ID)
+	r.trackProcessing(e, replaceExisting)
+	return r.state.Record(r.config.Partition, item.ExternalID, hash, doc.ID)
 }
 
-// importFile handles the import of a file
-func importFile(c *client.Client, filePath string, relPath string, fileInfo os.FileInfo, config ImportConfig) error {
-	// Generate a unique external ID based on the relative path
-	externalID := filepath.ToSlash(relPath)
-
-	// Handle existing documents based on flags
-	docExists := documentExists(c, config, externalID)
-	if docExists && !config.Force && !config.Replace {
-		fmt.Printf("warning: skipping file with existing document: %s\n", externalID)
-		return nil
+// createDocument uploads files using multipart form data and text through the raw
+// endpoint. It returns a nil document on a dry run.
+func (r *importRun) createDocument(ctx context.Context, item Record) (*client.Document, error) {
+	if r.config.DryRun {
+		return nil, nil
 	}
 
-	// Replace existing documents if --replace flag is used
-	if config.Replace && docExists {
-		err := replaceExistingDocuments(c, config, externalID)
+	item.Metadata["external_id"] = item.ExternalID
+
+	if item.Open != nil {
+		file, err := item.Open()
 		if err != nil {
-			fmt.Printf("failed to replace existing documents for file %s: %v\n", externalID, err)
-			return nil
+			return nil, err
 		}
+		defer file.Close()
+		return r.client.CreateDocumentContext(ctx, r.config.Partition, item.Name, file, item.FileName, item.Metadata, r.config.Mode)
 	}
+	return r.client.CreateDocumentRawContext(ctx, r.config.Partition, item.Name, item.Data, item.Metadata)
+}
 
-	// Read file content
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		fmt.Printf("error reading file %s: %v\n", filePath, err)
-		return nil
+// updateItemMetadata updates the metadata of doc, the existing document of item whose
+// content is unchanged, to the metadata of item
+func (r *importRun) updateItemMetadata(ctx context.Context, item Record, doc client.Document, hash string, e event, start time.Time, out io.Writer) error {
+	item.Metadata["content_hash"] = hash
+	item.Metadata["external_id"] = item.ExternalID
+	e.DocumentID = doc.ID
+	report := func(action string, format string, args ...interface{}) {
+		e.Action = action
+		e.DurationMS = since(start)
+		r.report.Item(out, e, format, args...)
 	}
 
-	// Skip empty files
-	if len(strings.TrimSpace(string(content))) == 0 {
-		fmt.Printf("warning: skipping empty file: %s\n", filePath)
+	changes := metadataChanges(doc.Metadata, item.Metadata)
+	if len(changes) == 0 {
+		report(actionUnchanged, "unchanged %s: %s\n", item.Kind, item.ExternalID)
 		return nil
 	}
+	e.Message = strings.Join(sortedKeys(changes), ", ")
 
-	metadata := map[string]interface{}{
-		"source_type": "files",
-		"path":        externalID,
-		"extension":   filepath.Ext(filePath),
-		"size":        fileInfo.Size(),
-		"mod_time":    fileInfo.ModTime().Format(time.RFC3339),
-	}
-
-	err = createDocument(c, externalID, filepath.Base(filePath), content, filepath.Base(filePath), metadata, config)
-	if err != nil {
-		fmt.Printf("failed to import file %s: %v\n", filePath, err)
+	if r.config.DryRun {
+		report(actionMetadataUpdated, "would update metadata of %s %s: %s\n", item.Kind, item.ExternalID, e.Message)
+		return nil
 	}
 
-	if config.Delay > 0 {
-		time.Sleep(time.Duration(config.Delay * float64(time.Second)))
+	if _, err := r.client.PatchDocumentMetadataContext(ctx, doc.ID, changes); err != nil {
+		e.Error = err.Error()
+		if isFatalImportError(err) {
+			report(actionFailed, "")
+			return fmt.Errorf("failed to update metadata of %s %s: %w", item.Kind, item.ExternalID, err)
+		}
+		e.DurationMS = since(start)
+		return r.failItem(out, e, err, "failed to update metadata of %s %s: %v\n", item.Kind, item.ExternalID, err)
 	}
 
-	return nil
+	report(actionMetadataUpdated, "updated metadata of %s %s: %s\n", item.Kind, item.ExternalID, e.Message)
+	return r.state.Record(r.config.Partition, item.ExternalID, hash, doc.ID)
 }
 
-// ImportZip imports all files from a zip archive without extracting them
-func ImportZip(c *client.Client, zipFile string, config ImportConfig) error {
-	fmt.Printf("Loading files from zip archive: %s\n", zipFile)
-
-	// Open the zip file
-	reader, err := zip.OpenReader(zipFile)
-	if err != nil {
-		return fmt.Errorf("failed to open ZIP file: %v", err)
+// updateDocument replaces the content of an existing document, then merges the
+// metadata of item into its metadata. The content is updated first, so that a failed
+// update does not leave the hash of the new content on the old one.
+func (r *importRun) updateDocument(ctx context.Context, docID string, item Record) error {
+	if r.config.DryRun {
+		return nil
 	}
-	defer reader.Close()
 
-	// Process each file in the zip
-	for _, file := range reader.File {
-		// Skip directories
-		if file.FileInfo().IsDir() {
-			continue
-		}
+	item.Metadata["external_id"] = item.ExternalID
 
-		// Generate a unique external ID based on the path within the zip
-		externalID := filepath.ToSlash(file.Name)
-
-		// Handle existing documents based on flags
-		docExists := documentExists(c, config, externalID)
-		if docExists && !config.Force && !config.Replace {
-			fmt.Printf("warning: skipping file with existing document: %s\n", externalID)
-			continue
-		}
-
-		// Replace existing documents if --replace flag is used
-		if config.Replace && docExists {
-			err := replaceExistingDocuments(c, config, externalID)
-			if err != nil {
-				fmt.Printf("failed to replace existing documents for file %s: %v\n", externalID, err)
-				continue
-			}
-		}
-
-		// Open the file within the zip
-		rc, err := file.Open()
+	if item.Open != nil {
+		file, err := item.Open()
 		if err != nil {
-			fmt.Printf("failed to open file in zip %s: %v\n", file.Name, err)
-			continue
+			return err
 		}
-
-		// Read file content
-		content, err := io.ReadAll(rc)
-		rc.Close()
+		err = r.client.UpdateDocumentFileContext(ctx, docID, file, item.FileName, r.config.Mode)
+		file.Close()
 		if err != nil {
-			fmt.Printf("failed to read file in zip %s: %v\n", file.Name, err)
-			continue
-		}
-
-		// Skip empty files
-		if len(strings.TrimSpace(string(content))) == 0 {
-			fmt.Printf("warning: skipping empty file: %s\n", file.Name)
-			continue
-		}
-
-		// Create metadata for the file
-		metadata := map[string]interface{}{
-			"source_type":     "zip",
-			"path":            externalID,
-			"extension":       filepath.Ext(file.Name),
-			"size":            file.UncompressedSize64,
-			"mod_time":        file.Modified.Format(time.RFC3339),
-			"compressed_size": file.CompressedSize64,
-			"zip_source":      filepath.Base(zipFile),
+			return err
 		}
+	} else if err := r.client.UpdateDocumentRawContext(ctx, docID, item.Data); err != nil {
+		return err
+	}
 
-		// Create the document using multipart form data
-		err = createDocument(c, externalID, filepath.Base(file.Name), content, file.Name, metadata, config)
-		if err != nil {
-			fmt.Printf("failed to import file %s: %v\n", file.Name, err)
-		}
+	_, err := r.client.PatchDocumentMetadataContext(ctx, docID, item.Metadata)
+	return err
+}
 
-		if config.Delay > 0 {
-			time.Sleep(time.Duration(config.Delay * float64(time.Second)))
-		}
+// recordContentHash returns the hash of the content of record, reading its file if it
+// has one, and whether the content is blank
+func recordContentHash(record Record) (string, bool, error) {
+	if record.Open == nil {
+		return readContentHash(strings.NewReader(record.Data))
 	}
 
-	return nil
+	file, err := record.Open()
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+	return readContentHash(file)
 }
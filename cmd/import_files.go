@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ragie/pkg/client"
+
+	"github.com/spf13/pflag"
+)
+
+// fileImportHelp documents the options shared by the 'files' and 'zip' import types
+const fileImportHelp = `
+
+Filtering:
+  A .ragieignore file at the root of the directory or archive lists the paths
+  that are not imported, in .gitignore syntax. The --exclude globs are applied
+  after it, and with --include only the files matching one of the include globs
+  are imported. A glob without a slash, such as '*.lock', matches at any depth.
+  Excluded files and directories are listed as skipped.
+
+Pruning:
+  With --prune, after a complete import in which no item failed, documents in
+  the partition whose file was not found during the import are deleted. Only
+  documents whose external ID is in the namespace of the import are considered,
+  e.g. files:<directory name> or zip:<archive name>, so other directories and
+  archives imported into the same partition are kept. Combine with --dry-run to
+  preview what would be deleted.`
+
+func init() {
+	RegisterImportSource(ImportSource{
+		Name:  "files",
+		Short: "Import a file or the files of a directory",
+		Long: `Imports files from a directory recursively or a file.
+All non-empty files will be imported as separate documents.
+Preserves file metadata including path, extension, size, and modification time.` + fileImportHelp,
+		Example: "  ragie import files path/to/documents/\n  ragie import files path/to/file.txt",
+		Flags:   fileImportFlags,
+		New:     newFilesImporter,
+	})
+}
+
+// fileImportFlags adds the flags of the 'files' and 'zip' import types
+func fileImportFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&prune, "prune", false, "Delete documents whose source file no longer exists in the directory or zip archive")
+	flags.StringArrayVar(&includes, "include", nil, "Only import files matching this glob, e.g. '*.md' or 'docs/**' (repeatable)")
+	flags.StringArrayVar(&excludes, "exclude", nil, "Do not import files or directories matching this glob, in .ragieignore syntax (repeatable)")
+}
+
+// filesImporter imports a single file, or the files of a directory recursively
+type filesImporter struct {
+	path   string
+	info   os.FileInfo
+	filter *importFilter
+}
+
+func newFilesImporter(path string, config ImportConfig) (Importer, error) {
+	// Check if path exists
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path: %v", err)
+	}
+	if config.Prune && !info.IsDir() {
+		return nil, fmt.Errorf("--prune requires a directory, not a single file")
+	}
+
+	// A single file is imported as given, only the files of a directory are filtered
+	i := &filesImporter{path: path, info: info}
+	if info.IsDir() {
+		var ignoreFile io.Reader
+		data, err := os.ReadFile(filepath.Join(path, IgnoreFileName))
+		if err == nil {
+			ignoreFile = bytes.NewReader(data)
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %v", IgnoreFileName, err)
+		}
+
+		i.filter, err = newImportFilter(config.Include, config.Exclude, ignoreFile)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(progressOutput(config.Output), "Loading files from directory: %s\n", path)
+	} else {
+		fmt.Fprintf(progressOutput(config.Output), "Loading file: %s\n", path)
+	}
+
+	return i, nil
+}
+
+// ImportFiles imports a file or all files from a directory recursively
+func ImportFiles(ctx context.Context, c *client.Client, path string, config ImportConfig) error {
+	return Import(ctx, c, "files", path, config)
+}
+
+// Namespace is files:<directory name>, or the name of the file's directory for a
+// single file, so that directories imported into the same partition do not collide
+func (i *filesImporter) Namespace() string {
+	dir := i.path
+	if !i.info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return "files:" + filepath.Base(dir)
+}
+
+func (i *filesImporter) PruneFilter() map[string]interface{} {
+	return map[string]interface{}{"source_type": "files"}
+}
+
+func (i *filesImporter) Import(ctx context.Context, sink RecordSink) error {
+	if !i.info.IsDir() {
+		// A single file is named like it would be in an import of its directory
+		return sink.Add(fileRecord(i.path, filepath.Base(i.path), i.info))
+	}
+
+	// Walk through the directory recursively
+	return filepath.Walk(i.path, func(filePath string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return sink.Fail(Record{Kind: "path", Name: filePath}, err)
+		}
+
+		relPath, err := filepath.Rel(i.path, filePath)
+		if err != nil {
+			return sink.Fail(Record{Kind: "path", Name: filePath}, err)
+		}
+		slashPath := filepath.ToSlash(relPath)
+
+		// Skip directories, and do not descend into excluded ones
+		if fileInfo.IsDir() {
+			if relPath != "." && i.filter.Excluded(slashPath, true) {
+				sink.Skip(Record{Kind: "directory", Name: slashPath}, "excluded")
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if relPath == IgnoreFileName {
+			return nil
+		}
+		if i.filter.Excluded(slashPath, false) {
+			sink.Skip(Record{Kind: "file", ExternalID: slashPath}, "excluded")
+			return nil
+		}
+
+		return sink.Add(fileRecord(filePath, relPath, fileInfo))
+	})
+}
+
+// fileRecord returns the document of a file, whose content is hashed and uploaded
+// by the import pipeline
+func fileRecord(filePath string, relPath string, fileInfo os.FileInfo) Record {
+	// Generate a unique external ID based on the relative path
+	externalID := filepath.ToSlash(relPath)
+
+	metadata := map[string]interface{}{
+		"source_type": "files",
+		"path":        externalID,
+		"extension":   filepath.Ext(filePath),
+		"size":        fileInfo.Size(),
+		"mod_time":    fileInfo.ModTime().Format(time.RFC3339),
+	}
+
+	return Record{
+		Kind:       "file",
+		ExternalID: externalID,
+		Name:       filepath.Base(filePath),
+		Open:       func() (io.ReadCloser, error) { return os.Open(filePath) },
+		FileName:   filepath.Base(filePath),
+		Metadata:   metadata,
+	}
+}
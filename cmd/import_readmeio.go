@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"ragie/pkg/client"
+)
+
+func init() {
+	RegisterImportSource(ImportSource{
+		Name:  "readmeio",
+		Short: "Import ReadmeIO documentation from a ZIP archive",
+		Long: `Imports ReadmeIO documentation from a ZIP archive.
+The ZIP should contain Markdown files with YAML frontmatter.
+Each Markdown file will be imported as a separate document, preserving metadata.`,
+		Example: "  ragie import readmeio path/to/readme-docs.zip",
+		New:     newReadmeIOImporter,
+	})
+}
+
+// readmeIOImporter imports the Markdown pages of a readme.io archive
+type readmeIOImporter struct {
+	reader *zip.ReadCloser
+}
+
+func newReadmeIOImporter(readmeZip string, config ImportConfig) (Importer, error) {
+	fmt.Fprintf(progressOutput(config.Output), "Loading readme.io ZIP file: %s\n", readmeZip)
+
+	reader, err := zip.OpenReader(readmeZip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ZIP file: %v", err)
+	}
+
+	return &readmeIOImporter{reader: reader}, nil
+}
+
+// ImportReadmeIO imports ReadmeIO data from a ZIP file
+func ImportReadmeIO(ctx context.Context, c *client.Client, readmeZip string, config ImportConfig) error {
+	return Import(ctx, c, "readmeio", readmeZip, config)
+}
+
+func (i *readmeIOImporter) Close() error {
+	return i.reader.Close()
+}
+
+func (i *readmeIOImporter) Import(ctx context.Context, sink RecordSink) error {
+	for _, file := range i.reader.File {
+		if !strings.HasSuffix(file.Name, ".md") {
+			continue
+		}
+
+		record, err := readmeIORecord(file)
+		if err != nil {
+			if err := sink.Fail(Record{Kind: "readme document", Name: file.Name}, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if record.ExternalID == "" {
+			sink.Skip(record, "no slug")
+			continue
+		}
+
+		if err := sink.Add(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readmeIORecord reads a single Markdown page and its frontmatter. The external ID
+// is empty if the page has no slug.
+func readmeIORecord(file *zip.File) (Record, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return Record{}, err
+	}
+	content, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return Record{}, err
+	}
+
+	contentStr := string(content)
+	metadata := map[string]interface{}{
+		"sourceType": "readmeio",
+	}
+
+	// Parse frontmatter
+	parts := strings.SplitN(contentStr, "---", 3)
+	if len(parts) >= 3 {
+		frontmatter := parts[1]
+		contentStr = parts[2]
+
+		for _, line := range strings.Split(frontmatter, "\n") {
+			if strings.Contains(line, ":") {
+				parts := strings.SplitN(line, ":", 2)
+				key := strings.TrimSpace(parts[0])
+				value := strings.Trim(strings.TrimSpace(parts[1]), "\"")
+				metadata[key] = value
+			}
+		}
+	}
+
+	docID, _ := metadata["slug"].(string)
+	if docID != "" {
+		metadata["readmeId"] = docID
+	}
+
+	title, _ := metadata["title"].(string)
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(file.Name), ".md")
+	}
+
+	return Record{
+		Kind:       "readme document",
+		ExternalID: docID,
+		Name:       title,
+		Data:       contentStr,
+		Metadata:   metadata,
+	}, nil
+}
@@ -1,8 +1,21 @@
 package cmd
 
 import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"ragie/pkg/client"
 
 	"github.com/spf13/cobra"
 )
@@ -75,3 +88,668 @@ func TestImportCmdFlagValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestImportConfigValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      ImportConfig
+		expectError bool
+	}{
+		{name: "no conflict handling", config: ImportConfig{}},
+		{name: "force", config: ImportConfig{Force: true}},
+		{name: "sync", config: ImportConfig{Sync: true}},
+		{name: "force and replace", config: ImportConfig{Force: true, Replace: true}, expectError: true},
+		{name: "sync and replace", config: ImportConfig{Sync: true, Replace: true}, expectError: true},
+		{name: "sync and force", config: ImportConfig{Sync: true, Force: true}, expectError: true},
+		{name: "sync and update", config: ImportConfig{Sync: true, Update: true}},
+		{name: "update and replace", config: ImportConfig{Update: true, Replace: true}, expectError: true},
+		{name: "update and force", config: ImportConfig{Update: true, Force: true}, expectError: true},
+		{name: "update metadata and replace", config: ImportConfig{UpdateMetadata: true, Replace: true}},
+		{name: "update metadata and force", config: ImportConfig{UpdateMetadata: true, Force: true}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error, but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestImportFilesSync(t *testing.T) {
+	api := newFakeAPI(t)
+	c := api.client()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"unchanged.txt": "same content",
+		"changed.txt":   "new content",
+		"new.txt":       "brand new",
+	})
+
+	api.add("", "unchanged.txt", map[string]interface{}{
+		"external_id":  filesID(dir, "unchanged.txt"),
+		"content_hash": contentHash([]byte("same content")),
+	})
+	changedID := api.add("", "changed.txt", map[string]interface{}{
+		"external_id":  filesID(dir, "changed.txt"),
+		"content_hash": contentHash([]byte("old content")),
+	})
+
+	config := ImportConfig{Sync: true, Concurrency: 2}
+	if err := ImportFiles(context.Background(), c, dir, config); err != nil {
+		t.Fatalf("Failed to import files: %v", err)
+	}
+
+	for _, externalID := range []string{"unchanged.txt", "changed.txt", "new.txt"} {
+		if docs := api.documents(filesID(dir, externalID)); len(docs) != 1 {
+			t.Errorf("Expected 1 document for %s, got %d", externalID, len(docs))
+		}
+	}
+
+	changed := api.documents(filesID(dir, "changed.txt"))
+	if len(changed) == 1 {
+		if changed[0].ID == changedID {
+			t.Errorf("Expected changed.txt to be replaced")
+		}
+		if changed[0].Content != "new content" {
+			t.Errorf("Expected new content for changed.txt, got '%s'", changed[0].Content)
+		}
+		if changed[0].Metadata["content_hash"] != contentHash([]byte("new content")) {
+			t.Errorf("Expected content_hash metadata to be updated")
+		}
+	}
+
+	if n := api.count("POST /documents"); n != 2 {
+		t.Errorf("Expected 2 uploads, got %d", n)
+	}
+}
+
+func TestImportDocumentIndex(t *testing.T) {
+	api := newFakeAPI(t)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c", "d.txt": "d"})
+
+	// More existing documents than fit on a page, and a document of another partition
+	for i := 0; i < 150; i++ {
+		api.add("docs", fmt.Sprintf("old-%d.txt", i), map[string]interface{}{"external_id": filesID(dir, fmt.Sprintf("old-%d.txt", i))})
+	}
+	existingID := api.add("docs", "a.txt", map[string]interface{}{"external_id": filesID(dir, "a.txt")})
+	api.add("other", "b.txt", map[string]interface{}{"external_id": filesID(dir, "b.txt")})
+
+	config := ImportConfig{Partition: "docs", Concurrency: 2}
+	if err := ImportFiles(context.Background(), api.client(), dir, config); err != nil {
+		t.Fatalf("Failed to import files: %v", err)
+	}
+
+	if docs := api.documents(filesID(dir, "a.txt")); len(docs) != 1 || docs[0].ID != existingID {
+		t.Errorf("Expected a.txt to be skipped as existing, got %d documents", len(docs))
+	}
+	if docs := api.documents(filesID(dir, "b.txt")); len(docs) != 2 {
+		t.Errorf("Expected b.txt to be created in partition docs, got %d documents", len(docs))
+	}
+	if n := api.count("GET /documents"); n != 2 {
+		t.Errorf("Expected the partition to be listed once in 2 pages, got %d list requests", n)
+	}
+	if n := api.count("POST /documents"); n != 3 {
+		t.Errorf("Expected 3 uploads, got %d", n)
+	}
+}
+
+func TestImportDocumentIndexFailed(t *testing.T) {
+	api := newFakeAPI(t)
+	api.failListing()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a"})
+
+	err := ImportFiles(context.Background(), api.client(), dir, ImportConfig{})
+	if err == nil || !strings.Contains(err.Error(), "failed to index existing documents") {
+		t.Errorf("Expected the import to abort when existing documents cannot be listed, got %v", err)
+	}
+	if n := api.count("POST /documents"); n != 0 {
+		t.Errorf("Expected no uploads, got %d", n)
+	}
+}
+
+func TestImportFilesPrune(t *testing.T) {
+	tests := []struct {
+		name            string
+		dryRun          bool
+		expectedRemoved bool
+	}{
+		{name: "prune", dryRun: false, expectedRemoved: true},
+		{name: "dry run", dryRun: true, expectedRemoved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureStdout(t)
+			api := newFakeAPI(t)
+			c := api.client()
+
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{
+				"kept.txt":       "kept",
+				"nested/new.txt": "new",
+			})
+
+			api.add("", "kept.txt", map[string]interface{}{
+				"external_id": filesID(dir, "kept.txt"), "source_type": "files", "path": "kept.txt",
+				"content_hash": contentHash([]byte("kept")),
+			})
+			api.add("", "removed.txt", map[string]interface{}{
+				"external_id": filesID(dir, "removed.txt"), "source_type": "files", "path": "removed.txt",
+			})
+			api.add("", "other.txt", map[string]interface{}{
+				"external_id": "zip:other.zip:other.txt", "source_type": "zip", "path": "other.txt",
+			})
+			api.add("", "removed.txt", map[string]interface{}{
+				"external_id": "files:handbook:removed.txt", "source_type": "files", "path": "removed.txt",
+			})
+			api.add("staging", "removed.txt", map[string]interface{}{
+				"external_id": filesID(dir, "removed.txt"), "source_type": "files", "path": "removed.txt",
+			})
+
+			config := ImportConfig{Sync: true, Prune: true, DryRun: tt.dryRun, Output: OutputNDJSON}
+			if err := ImportFiles(context.Background(), c, dir, config); err != nil {
+				t.Fatalf("Failed to import files: %v", err)
+			}
+
+			// The document in the other partition is never pruned
+			expected := 2
+			if tt.expectedRemoved {
+				expected = 1
+			}
+			if docs := api.documents(filesID(dir, "removed.txt")); len(docs) != expected {
+				t.Errorf("Expected %d documents for removed.txt, got %d", expected, len(docs))
+			}
+			if len(api.documents(filesID(dir, "kept.txt"))) != 1 {
+				t.Errorf("Expected kept.txt to be kept")
+			}
+
+			// Pruned documents are reported with their external ID and their path
+			var pruned []event
+			scanner := bufio.NewScanner(buf)
+			for scanner.Scan() {
+				var e event
+				if err := json.Unmarshal(scanner.Bytes(), &e); err == nil && e.Action == actionPruned {
+					pruned = append(pruned, e)
+				}
+			}
+			if len(pruned) != 1 || pruned[0].ExternalID != filesID(dir, "removed.txt") || pruned[0].Message != "removed.txt" {
+				t.Errorf("Expected removed.txt to be reported as pruned, got %+v", pruned)
+			}
+			if len(api.documents("zip:other.zip:other.txt")) != 1 {
+				t.Errorf("Expected document with another source_type to be kept")
+			}
+			if len(api.documents("files:handbook:removed.txt")) != 1 {
+				t.Errorf("Expected document of another directory to be kept")
+			}
+		})
+	}
+}
+
+func TestImportFilesPruneFailedItems(t *testing.T) {
+	buf := captureStdout(t)
+	api := newFakeAPI(t)
+	api.failUploads("a.txt")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	api.add("", "removed.txt", map[string]interface{}{
+		"external_id": filesID(dir, "removed.txt"), "source_type": "files", "path": "removed.txt",
+	})
+
+	err := ImportFiles(context.Background(), api.client(), dir, ImportConfig{Prune: true})
+
+	var itemsFailed *ItemsFailedError
+	if !errors.As(err, &itemsFailed) {
+		t.Fatalf("Expected ItemsFailedError, got %v", err)
+	}
+	if len(api.documents(filesID(dir, "removed.txt"))) != 1 {
+		t.Errorf("Expected no documents to be pruned after a failed item")
+	}
+	if !strings.Contains(buf.String(), "Skipping prune, as 1 items failed") {
+		t.Errorf("Expected the skipped prune to be reported, got:\n%s", buf.String())
+	}
+}
+
+func TestImportFilesPruneSingleFile(t *testing.T) {
+	api := newFakeAPI(t)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a"})
+
+	err := ImportFiles(context.Background(), api.client(), filepath.Join(dir, "a.txt"), ImportConfig{Prune: true})
+	if err == nil {
+		t.Errorf("Expected error when pruning a single file")
+	}
+}
+
+func TestImportFilesItemsFailed(t *testing.T) {
+	tests := []struct {
+		name            string
+		failFast        bool
+		expectedCreated int
+	}{
+		{name: "continue", failFast: false, expectedCreated: 2},
+		{name: "fail fast", failFast: true, expectedCreated: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			api.failUploads("b.txt")
+
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+
+			err := ImportFiles(context.Background(), api.client(), dir, ImportConfig{FailFast: tt.failFast})
+
+			var itemsFailed *ItemsFailedError
+			if !errors.As(err, &itemsFailed) {
+				t.Fatalf("Expected ItemsFailedError, got %v", err)
+			}
+			if itemsFailed.Failed != 1 || itemsFailed.FailFast != tt.failFast {
+				t.Errorf("Expected 1 failed item with fail fast %v, got %+v", tt.failFast, itemsFailed)
+			}
+
+			created := len(api.documents(filesID(dir, "a.txt"))) + len(api.documents(filesID(dir, "c.txt")))
+			if created != tt.expectedCreated {
+				t.Errorf("Expected %d documents to be created, got %d", tt.expectedCreated, created)
+			}
+		})
+	}
+}
+
+func TestImportFilesWait(t *testing.T) {
+	defer func(interval time.Duration) { waitPollInterval = interval }(waitPollInterval)
+	waitPollInterval = time.Millisecond
+
+	tests := []struct {
+		name           string
+		processedAs    map[string]string
+		expectedFailed int
+	}{
+		{name: "all ready", expectedFailed: 0},
+		{name: "processing failed", processedAs: map[string]string{"b.txt": "failed"}, expectedFailed: 1},
+		{name: "timeout", processedAs: map[string]string{"a.txt": "pending", "c.txt": "pending"}, expectedFailed: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			for name, status := range tt.processedAs {
+				api.processAs(name, status)
+			}
+
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+
+			config := ImportConfig{Wait: true, WaitTimeout: 50 * time.Millisecond}
+			err := ImportFiles(context.Background(), api.client(), dir, config)
+
+			if tt.expectedFailed == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, but got: %v", err)
+				}
+			} else {
+				var itemsFailed *ItemsFailedError
+				if !errors.As(err, &itemsFailed) {
+					t.Fatalf("Expected ItemsFailedError, got %v", err)
+				}
+				if itemsFailed.Failed != tt.expectedFailed || itemsFailed.Total != 3 {
+					t.Errorf("Expected %d of 3 items to fail, got %+v", tt.expectedFailed, itemsFailed)
+				}
+			}
+
+			// Each document is pending on the first poll
+			if n := api.count("GET /documents/"); n < 6 {
+				t.Errorf("Expected each document to be polled at least twice, got %d polls", n)
+			}
+		})
+	}
+}
+
+func TestImportFilesReplace(t *testing.T) {
+	defer func(interval time.Duration) { waitPollInterval = interval }(waitPollInterval)
+	waitPollInterval = time.Millisecond
+
+	tests := []struct {
+		name        string
+		wait        bool
+		processedAs string
+		undeletable bool
+		expectNew   bool
+		expectError bool
+	}{
+		{name: "replaced", expectNew: true},
+		{name: "replaced once processed", wait: true, expectNew: true},
+		{name: "processing failed", wait: true, processedAs: "failed", expectError: true},
+		{name: "delete failed", undeletable: true, expectError: true},
+		{name: "delete failed once processed", wait: true, undeletable: true, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			if tt.processedAs != "" {
+				api.processAs("a.txt", tt.processedAs)
+			}
+
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{"a.txt": "new"})
+
+			// Two versions exist, more than one of which could be left behind
+			firstID := api.add("", "a.txt", map[string]interface{}{"external_id": filesID(dir, "a.txt")})
+			secondID := api.add("", "a.txt", map[string]interface{}{"external_id": filesID(dir, "a.txt")})
+			if tt.undeletable {
+				api.failDelete(secondID)
+			}
+
+			config := ImportConfig{Replace: true, Wait: tt.wait, WaitTimeout: time.Second}
+			err := ImportFiles(context.Background(), api.client(), dir, config)
+
+			var itemsFailed *ItemsFailedError
+			if tt.expectError != errors.As(err, &itemsFailed) {
+				t.Fatalf("Expected failed items %v, got %v", tt.expectError, err)
+			}
+
+			// The new version is uploaded before anything is deleted
+			api.mu.Lock()
+			requests := strings.Join(api.requests, "\n")
+			api.mu.Unlock()
+			if upload := strings.Index(requests, "POST /documents"); upload < 0 || upload > strings.Index(requests, "DELETE ") {
+				t.Errorf("Expected the new document to be uploaded before deleting, got requests:\n%s", requests)
+			}
+
+			docs := api.documents(filesID(dir, "a.txt"))
+			var ids []string
+			hasNew := false
+			for _, doc := range docs {
+				ids = append(ids, doc.ID)
+				if doc.ID != firstID && doc.ID != secondID {
+					hasNew = true
+				}
+			}
+			if hasNew != tt.expectNew {
+				t.Errorf("Expected new document %v, got documents %v", tt.expectNew, ids)
+			}
+			if tt.expectNew && len(docs) != 1 {
+				t.Errorf("Expected only the new document to be left, got %v", ids)
+			}
+			if !tt.expectNew && !tt.undeletable && len(docs) != 2 {
+				t.Errorf("Expected the existing documents to be kept, got %v", ids)
+			}
+		})
+	}
+}
+
+func TestImportUpdate(t *testing.T) {
+	tests := []struct {
+		name            string
+		sync            bool
+		expectedActions map[string]int
+	}{
+		{name: "update", expectedActions: map[string]int{actionUpdated: 2, actionCreated: 1}},
+		{name: "sync", sync: true, expectedActions: map[string]int{actionUpdated: 1, actionUnchanged: 1, actionCreated: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureStdout(t)
+			api := newFakeAPI(t)
+
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{
+				"changed.txt":   "new content",
+				"unchanged.txt": "same content",
+				"new.txt":       "brand new",
+			})
+
+			changedID := api.add("", "changed.txt", map[string]interface{}{
+				"external_id":  filesID(dir, "changed.txt"),
+				"content_hash": contentHash([]byte("old content")),
+				"stale":        "kept",
+			})
+			unchangedID := api.add("", "unchanged.txt", map[string]interface{}{
+				"external_id":  filesID(dir, "unchanged.txt"),
+				"content_hash": contentHash([]byte("same content")),
+			})
+
+			config := ImportConfig{Update: true, Sync: tt.sync, Output: OutputNDJSON}
+			if err := ImportFiles(context.Background(), api.client(), dir, config); err != nil {
+				t.Fatalf("Failed to import files: %v", err)
+			}
+
+			// Documents keep their ID, with the new content and metadata
+			changed := api.documents(filesID(dir, "changed.txt"))
+			if len(changed) != 1 || changed[0].ID != changedID {
+				t.Fatalf("Expected changed.txt to keep document %s, got %d documents", changedID, len(changed))
+			}
+			if changed[0].Content != "new content" {
+				t.Errorf("Expected new content for changed.txt, got '%s'", changed[0].Content)
+			}
+			if changed[0].Metadata["content_hash"] != contentHash([]byte("new content")) || changed[0].Metadata["source_type"] != "files" {
+				t.Errorf("Expected the metadata of changed.txt to be updated, got %v", changed[0].Metadata)
+			}
+			if changed[0].Metadata["stale"] != "kept" {
+				t.Errorf("Expected metadata not set by the import to be kept, got %v", changed[0].Metadata)
+			}
+			if unchanged := api.documents(filesID(dir, "unchanged.txt")); len(unchanged) != 1 || unchanged[0].ID != unchangedID {
+				t.Errorf("Expected unchanged.txt to keep document %s", unchangedID)
+			}
+			if n := api.count("DELETE "); n != 0 {
+				t.Errorf("Expected no documents to be deleted, got %d deletes", n)
+			}
+
+			actions := map[string]int{}
+			scanner := bufio.NewScanner(buf)
+			for scanner.Scan() {
+				var e event
+				if err := json.Unmarshal(scanner.Bytes(), &e); err == nil && e.Type == "item" {
+					actions[e.Action]++
+				}
+			}
+			if !reflect.DeepEqual(actions, tt.expectedActions) {
+				t.Errorf("Expected actions %v, got %v", tt.expectedActions, actions)
+			}
+		})
+	}
+}
+
+func TestImportUpdateMetadata(t *testing.T) {
+	api := newFakeAPI(t)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"unchanged.txt": "same content",
+		"changed.txt":   "new content",
+	})
+
+	// Imported earlier, before the metadata had a source_type
+	unchangedID := api.add("", "unchanged.txt", map[string]interface{}{
+		"external_id":  filesID(dir, "unchanged.txt"),
+		"content_hash": contentHash([]byte("same content")),
+	})
+	changedID := api.add("", "changed.txt", map[string]interface{}{
+		"external_id":  filesID(dir, "changed.txt"),
+		"content_hash": contentHash([]byte("old content")),
+	})
+
+	config := ImportConfig{Sync: true, UpdateMetadata: true}
+	if err := ImportFiles(context.Background(), api.client(), dir, config); err != nil {
+		t.Fatalf("Failed to import files: %v", err)
+	}
+
+	unchanged := api.documents(filesID(dir, "unchanged.txt"))
+	if len(unchanged) != 1 || unchanged[0].ID != unchangedID {
+		t.Fatalf("Expected unchanged.txt to keep document %s", unchangedID)
+	}
+	if unchanged[0].Metadata["source_type"] != "files" || unchanged[0].Metadata["path"] != "unchanged.txt" {
+		t.Errorf("Expected the metadata of unchanged.txt to be updated, got %v", unchanged[0].Metadata)
+	}
+	if changed := api.documents(filesID(dir, "changed.txt")); len(changed) != 1 || changed[0].ID == changedID {
+		t.Errorf("Expected changed.txt to be replaced")
+	}
+	if n := api.count("POST /documents"); n != 1 {
+		t.Errorf("Expected only changed.txt to be uploaded, got %d uploads", n)
+	}
+
+	// A second run finds nothing to update
+	if err := ImportFiles(context.Background(), api.client(), dir, config); err != nil {
+		t.Fatalf("Failed to import files again: %v", err)
+	}
+	if n := api.count("PATCH "); n != 1 {
+		t.Errorf("Expected a single metadata update over both runs, got %d", n)
+	}
+}
+
+func TestReopenReader(t *testing.T) {
+	opened := 0
+	r := &reopenReader{open: func() (io.ReadCloser, error) {
+		opened++
+		return io.NopCloser(strings.NewReader("zip entry")), nil
+	}}
+	defer r.Close()
+
+	first, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if pos, _ := r.Seek(0, io.SeekCurrent); pos != int64(len(first)) {
+		t.Errorf("Expected offset %d, got %d", len(first), pos)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek to start: %v", err)
+	}
+	second, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read again: %v", err)
+	}
+
+	if string(first) != "zip entry" || string(second) != "zip entry" {
+		t.Errorf("Expected 'zip entry' twice, got '%s' and '%s'", first, second)
+	}
+	if opened != 2 {
+		t.Errorf("Expected the file to be opened twice, got %d", opened)
+	}
+	if _, err := r.Seek(3, io.SeekStart); err == nil {
+		t.Errorf("Expected error for unsupported seek")
+	}
+}
+
+func TestImportFilesAndZipFilter(t *testing.T) {
+	files := map[string]string{
+		IgnoreFileName:                   "node_modules/\n*.lock\n",
+		"README.md":                      "readme",
+		"docs/guide.md":                  "guide",
+		"docs/notes.txt":                 "notes",
+		"yarn.lock":                      "lock",
+		"node_modules/pkg/README.md":     "dependency",
+		".git/HEAD":                      "ref: refs/heads/main",
+		"docs/drafts/unfinished.md":      "draft",
+		"docs/drafts/also-unfinished.md": "draft",
+	}
+	config := ImportConfig{Include: []string{"*.md"}, Exclude: []string{".git/", "drafts/"}}
+	expected := []string{"README.md", "docs/guide.md"}
+
+	importers := []struct {
+		name       string
+		source     func(t *testing.T) string
+		run        func(ctx context.Context, c *client.Client, source string, config ImportConfig) error
+		externalID func(source string, path string) string
+	}{
+		{
+			name: "files",
+			source: func(t *testing.T) string {
+				dir := t.TempDir()
+				writeTestFiles(t, dir, files)
+				return dir
+			},
+			run:        ImportFiles,
+			externalID: filesID,
+		},
+		{
+			name: "zip",
+			source: func(t *testing.T) string {
+				return writeTestZip(t, files)
+			},
+			run: ImportZip,
+			externalID: func(source string, path string) string {
+				return "zip:test.zip:" + path
+			},
+		},
+	}
+
+	for _, importer := range importers {
+		t.Run(importer.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			source := importer.source(t)
+			if err := importer.run(context.Background(), api.client(), source, config); err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+
+			for path := range files {
+				imported := len(api.documents(importer.externalID(source, path))) > 0
+				shouldImport := false
+				for _, e := range expected {
+					shouldImport = shouldImport || e == path
+				}
+				if imported != shouldImport {
+					t.Errorf("Expected %s imported=%v, got %v", path, shouldImport, imported)
+				}
+			}
+		})
+	}
+}
+
+func writeTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s to zip: %v", name, err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to write zip file: %v", err)
+	}
+	return path
+}
+
+// filesID returns the external ID of the file at path imported from the directory dir
+func filesID(dir string, path string) string {
+	return "files:" + filepath.Base(dir) + ":" + path
+}
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ragie/pkg/client"
+
+	"github.com/beevik/etree"
+	"github.com/spf13/pflag"
+)
+
+// Post types and statuses imported from a WordPress export by default
+var (
+	defaultWordPressPostTypes = []string{"post", "page"}
+	defaultWordPressStatuses  = []string{"publish"}
+)
+
+func init() {
+	RegisterImportSource(ImportSource{
+		Name:  "wordpress",
+		Short: "Import posts and pages from a WordPress XML export",
+		Long: `Imports WordPress content from an XML export file (WXR format), as created
+by Tools > Export in the WordPress admin.
+Each post/page will be imported as a separate document with its title, excerpt
+and content.
+
+Only published posts and pages are imported by default. Use --post-types and
+--status to import other items, e.g. --status publish,draft. Items of other
+types or statuses are listed as skipped.
+
+Metadata:
+  Each document has the metadata sourceType ('wordpress'), title, url (the
+  permalink), postId, postType, status, author, publishedAt (RFC 3339),
+  categories and tags. The external ID is the item's guid, which does not
+  change when a post is renamed.`,
+		Example: "  ragie import wordpress path/to/wordpress-export.xml\n  ragie import wordpress export.xml --post-types post --status publish,private",
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringSliceVar(&postTypes, "post-types", defaultWordPressPostTypes, "Post types to import, e.g. post,page,product")
+			flags.StringSliceVar(&postStatuses, "status", defaultWordPressStatuses, "Statuses of the posts to import, e.g. publish,draft,private")
+		},
+		New: newWordPressImporter,
+	})
+}
+
+// wordpressImporter imports the posts and pages of a WordPress export
+type wordpressImporter struct {
+	root      *etree.Element
+	postTypes []string
+	statuses  []string
+}
+
+func newWordPressImporter(wordpressFile string, config ImportConfig) (Importer, error) {
+	fmt.Fprintf(progressOutput(config.Output), "Loading WordPress XML file: %s\n", wordpressFile)
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromFile(wordpressFile); err != nil {
+		return nil, fmt.Errorf("failed to read XML file: %v", err)
+	}
+
+	root := doc.Root()
+	if root == nil {
+		return nil, fmt.Errorf("empty XML file")
+	}
+
+	i := &wordpressImporter{root: root, postTypes: config.PostTypes, statuses: config.Statuses}
+	if len(i.postTypes) == 0 {
+		i.postTypes = defaultWordPressPostTypes
+	}
+	if len(i.statuses) == 0 {
+		i.statuses = defaultWordPressStatuses
+	}
+	return i, nil
+}
+
+// ImportWordPress imports WordPress data from an XML file
+func ImportWordPress(ctx context.Context, c *client.Client, wordpressFile string, config ImportConfig) error {
+	return Import(ctx, c, "wordpress", wordpressFile, config)
+}
+
+func (i *wordpressImporter) Import(ctx context.Context, sink RecordSink) error {
+	// Exports without an RSS channel use the simplified <posts><post> format
+	channel := i.root.FindElement("channel")
+	if i.root.Tag != "rss" || channel == nil {
+		for _, item := range i.root.FindElements(".//post") {
+			if err := sink.Add(wordPressRecord(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	authors := wxrAuthors(channel)
+	for _, item := range channel.SelectElements("item") {
+		record := wxrRecord(item, authors)
+
+		postType, _ := record.Metadata["postType"].(string)
+		status, _ := record.Metadata["status"].(string)
+		if !slices.Contains(i.postTypes, postType) {
+			sink.Skip(record, "post type "+postType)
+			continue
+		}
+		if !slices.Contains(i.statuses, status) {
+			sink.Skip(record, "status "+status)
+			continue
+		}
+
+		if err := sink.Add(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wordPressRecord returns the document of a single post or page
+func wordPressRecord(item *etree.Element) Record {
+	metadata := map[string]interface{}{
+		"sourceType": "wordpress",
+	}
+
+	urlElem := item.FindElement("url")
+	url := ""
+	if urlElem != nil {
+		url = urlElem.Text()
+	}
+	metadata["url"] = url
+
+	titleElem := item.FindElement("title")
+	title := ""
+	if titleElem != nil {
+		title = titleElem.Text()
+	}
+	metadata["title"] = title
+
+	descElem := item.FindElement("description")
+	desc := ""
+	if descElem != nil {
+		desc = descElem.Text()
+	}
+
+	contentElem := item.FindElement("content")
+	content := ""
+	if contentElem != nil {
+		content = contentElem.Text()
+	}
+
+	data := strings.Join([]string{title, desc, content}, "\n\n")
+
+	return Record{
+		Kind:       "post",
+		ExternalID: url,
+		Name:       title,
+		Data:       data,
+		Metadata:   metadata,
+	}
+}
+
+// wxrRecord returns the document of an item of a WXR export. authors maps the
+// login names of the export to display names.
+func wxrRecord(item *etree.Element, authors map[string]string) Record {
+	title := childText(item, "title")
+	permalink := childText(item, "link")
+	postType := childText(item, "wp:post_type")
+
+	// The guid is stable, the permalink changes with the slug and is not set for drafts
+	externalID := childText(item, "guid")
+	if externalID == "" {
+		externalID = permalink
+	}
+
+	author := childText(item, "dc:creator")
+	if name, ok := authors[author]; ok {
+		author = name
+	}
+
+	var categories, tags []string
+	for _, category := range item.SelectElements("category") {
+		name := strings.TrimSpace(category.Text())
+		switch category.SelectAttrValue("domain", "") {
+		case "category":
+			categories = append(categories, name)
+		case "post_tag":
+			tags = append(tags, name)
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"sourceType": "wordpress",
+		"title":      title,
+		"url":        permalink,
+		"postId":     childText(item, "wp:post_id"),
+		"postType":   postType,
+		"status":     childText(item, "wp:status"),
+		"author":     author,
+		"categories": nonNil(categories),
+		"tags":       nonNil(tags),
+	}
+	if published := wxrPublishDate(item); !published.IsZero() {
+		metadata["publishedAt"] = published.Format(time.RFC3339)
+	}
+
+	// The document is the title, the excerpt if there is one and the content
+	parts := []string{title}
+	if excerpt := childText(item, "excerpt:encoded"); excerpt != "" {
+		parts = append(parts, excerpt)
+	}
+	parts = append(parts, childText(item, "content:encoded"))
+
+	return Record{
+		Kind:       postType,
+		ExternalID: externalID,
+		Name:       title,
+		Data:       strings.Join(parts, "\n\n"),
+		Metadata:   metadata,
+	}
+}
+
+// wxrAuthors returns the display names of the authors of a WXR channel by login
+func wxrAuthors(channel *etree.Element) map[string]string {
+	authors := map[string]string{}
+	for _, author := range channel.SelectElements("wp:author") {
+		login := childText(author, "wp:author_login")
+		name := childText(author, "wp:author_display_name")
+		if login != "" && name != "" {
+			authors[login] = name
+		}
+	}
+	return authors
+}
+
+// wxrPublishDate returns when an item was published, or the zero time for drafts
+func wxrPublishDate(item *etree.Element) time.Time {
+	// Unpublished items have the date 0000-00-00 00:00:00, which does not parse
+	if t, err := time.Parse(time.DateTime, childText(item, "wp:post_date_gmt")); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.RFC1123Z, childText(item, "pubDate")); err == nil {
+		return t.UTC()
+	}
+	return time.Time{}
+}
+
+// childText returns the trimmed text of the first child with the given tag, which
+// may have a namespace prefix such as "wp:status"
+func childText(e *etree.Element, tag string) string {
+	child := e.SelectElement(tag)
+	if child == nil {
+		return ""
+	}
+	return strings.TrimSpace(child.Text())
+}
+
+// nonNil returns an empty slice for nil, so that empty lists are sent as [] rather
+// than null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
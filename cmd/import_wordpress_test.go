@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// recordingSink collects the records of an importer
+type recordingSink struct {
+	added   []Record
+	skipped map[string]string
+}
+
+func (s *recordingSink) Add(record Record) error {
+	s.added = append(s.added, record)
+	return nil
+}
+
+func (s *recordingSink) Skip(record Record, reason string) {
+	if s.skipped == nil {
+		s.skipped = map[string]string{}
+	}
+	s.skipped[record.ExternalID] = reason
+}
+
+func (s *recordingSink) Fail(record Record, err error) error {
+	return err
+}
+
+func TestWordPressImporterWXR(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          ImportConfig
+		expectedAdded   []string
+		expectedSkipped map[string]string
+	}{
+		{
+			name:          "published posts and pages by default",
+			config:        ImportConfig{},
+			expectedAdded: []string{"https://example.com/?p=10", "https://example.com/?page_id=11"},
+			expectedSkipped: map[string]string{
+				"https://example.com/?p=12":                                 "status draft",
+				"https://example.com/wp-content/uploads/2024/01/header.png": "post type attachment",
+			},
+		},
+		{
+			name:          "drafts of posts",
+			config:        ImportConfig{PostTypes: []string{"post"}, Statuses: []string{"publish", "draft"}},
+			expectedAdded: []string{"https://example.com/?p=10", "https://example.com/?p=12"},
+			expectedSkipped: map[string]string{
+				"https://example.com/?page_id=11":                           "post type page",
+				"https://example.com/wp-content/uploads/2024/01/header.png": "post type attachment",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureStdout(t)
+			importer, err := newWordPressImporter("../testdata/wordpress_wxr_sample.xml", tt.config)
+			if err != nil {
+				t.Fatalf("newWordPressImporter returned error: %v", err)
+			}
+
+			sink := &recordingSink{}
+			if err := importer.Import(context.Background(), sink); err != nil {
+				t.Fatalf("Import returned error: %v", err)
+			}
+
+			var added []string
+			for _, record := range sink.added {
+				added = append(added, record.ExternalID)
+			}
+			if !reflect.DeepEqual(added, tt.expectedAdded) {
+				t.Errorf("Expected added records %v, got %v", tt.expectedAdded, added)
+			}
+			if !reflect.DeepEqual(sink.skipped, tt.expectedSkipped) {
+				t.Errorf("Expected skipped records %v, got %v", tt.expectedSkipped, sink.skipped)
+			}
+		})
+	}
+}
+
+func TestWXRRecord(t *testing.T) {
+	captureStdout(t)
+	importer, err := newWordPressImporter("../testdata/wordpress_wxr_sample.xml", ImportConfig{Statuses: []string{"publish", "draft"}})
+	if err != nil {
+		t.Fatalf("newWordPressImporter returned error: %v", err)
+	}
+	sink := &recordingSink{}
+	if err := importer.Import(context.Background(), sink); err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if len(sink.added) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(sink.added))
+	}
+
+	post := sink.added[0]
+	if post.Kind != "post" || post.Name != "First Test Post" {
+		t.Errorf("Expected post 'First Test Post', got %s '%s'", post.Kind, post.Name)
+	}
+	expectedData := "First Test Post\n\nThe excerpt of the first post\n\n<p>This is the full content of the first post.</p>"
+	if post.Data != expectedData {
+		t.Errorf("Expected data %q, got %q", expectedData, post.Data)
+	}
+	expectedMetadata := map[string]interface{}{
+		"sourceType":  "wordpress",
+		"title":       "First Test Post",
+		"url":         "https://example.com/2024/01/15/first-post/",
+		"postId":      "10",
+		"postType":    "post",
+		"status":      "publish",
+		"author":      "Jane Doe",
+		"publishedAt": "2024-01-15T09:30:00Z",
+		"categories":  []string{"News"},
+		"tags":        []string{"Go", "CLI"},
+	}
+	if !reflect.DeepEqual(post.Metadata, expectedMetadata) {
+		t.Errorf("Expected metadata %v, got %v", expectedMetadata, post.Metadata)
+	}
+
+	// Authors missing from the channel keep their login, and pages without excerpt or terms
+	page := sink.added[1]
+	if page.Metadata["author"] != "editor" {
+		t.Errorf("Expected author 'editor', got '%v'", page.Metadata["author"])
+	}
+	if page.Data != "About\n\n<p>About this blog.</p>" {
+		t.Errorf("Expected data without excerpt, got %q", page.Data)
+	}
+	if tags := page.Metadata["tags"]; !reflect.DeepEqual(tags, []string{}) {
+		t.Errorf("Expected no tags, got %v", tags)
+	}
+
+	// Drafts have no publish date
+	draft := sink.added[2]
+	if _, ok := draft.Metadata["publishedAt"]; ok {
+		t.Errorf("Expected no publishedAt for a draft, got %v", draft.Metadata["publishedAt"])
+	}
+}
+
+func TestWordPressImporterLegacy(t *testing.T) {
+	captureStdout(t)
+	importer, err := newWordPressImporter("../testdata/wordpress_sample.xml", ImportConfig{})
+	if err != nil {
+		t.Fatalf("newWordPressImporter returned error: %v", err)
+	}
+	sink := &recordingSink{}
+	if err := importer.Import(context.Background(), sink); err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+
+	if len(sink.added) == 0 || sink.added[0].ExternalID != "https://example.com/first-post" {
+		t.Fatalf("Expected the posts of the simplified format, got %+v", sink.added)
+	}
+	if sink.added[0].Metadata["sourceType"] != "wordpress" {
+		t.Errorf("Expected sourceType 'wordpress', got '%v'", sink.added[0].Metadata["sourceType"])
+	}
+}
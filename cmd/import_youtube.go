@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"ragie/pkg/client"
+)
+
+func init() {
+	RegisterImportSource(ImportSource{
+		Name:  "youtube",
+		Short: "Import YouTube video transcripts from a JSON file",
+		Long: `Imports YouTube video transcripts and metadata from a JSON file.
+The JSON file should contain an array of objects with videoId, title, and captions fields.
+Each video will be imported as a separate document with its transcript and metadata.`,
+		Example: "  ragie import youtube path/to/youtube_videos.json",
+		New:     newYouTubeImporter,
+	})
+}
+
+// youtubeImporter imports the videos of a JSON file
+type youtubeImporter struct {
+	items []map[string]interface{}
+}
+
+func newYouTubeImporter(youtubeFile string, config ImportConfig) (Importer, error) {
+	fmt.Fprintf(progressOutput(config.Output), "Loading YouTube JSON file: %s\n", youtubeFile)
+
+	data, err := os.ReadFile(youtubeFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	var items []map[string]interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	return &youtubeImporter{items: items}, nil
+}
+
+// ImportYouTube imports YouTube data from a JSON file
+func ImportYouTube(ctx context.Context, c *client.Client, youtubeFile string, config ImportConfig) error {
+	return Import(ctx, c, "youtube", youtubeFile, config)
+}
+
+func (i *youtubeImporter) Import(ctx context.Context, sink RecordSink) error {
+	for _, item := range i.items {
+		videoID, ok := item["videoId"].(string)
+		if !ok || videoID == "" {
+			sink.Skip(Record{Kind: "video"}, "no videoId")
+			continue
+		}
+
+		title, _ := item["title"].(string)
+		captions, _ := item["captions"].([]interface{})
+
+		// The transcript is the title followed by the captions
+		var content strings.Builder
+		if title != "" {
+			content.WriteString(title)
+			content.WriteString("\n\n")
+		}
+
+		for _, cap := range captions {
+			if str, ok := cap.(string); ok && str != "" {
+				content.WriteString(str)
+				content.WriteString("\n")
+			}
+		}
+
+		if err := sink.Add(Record{
+			Kind:       "video",
+			ExternalID: videoID,
+			Name:       title,
+			Data:       content.String(),
+			Metadata: map[string]interface{}{
+				"title": title,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
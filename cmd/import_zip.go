@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"ragie/pkg/client"
+)
+
+func init() {
+	RegisterImportSource(ImportSource{
+		Name:  "zip",
+		Short: "Import the files of a zip archive",
+		Long: `Imports all files from a zip archive without extracting them.
+Each file will be imported as a separate document.
+Preserves file metadata including path, extension, size, and modification time.` + fileImportHelp,
+		Example: "  ragie import zip path/to/documents.zip",
+		Flags:   fileImportFlags,
+		New:     newZipImporter,
+	})
+}
+
+// zipImporter imports the files of a zip archive
+type zipImporter struct {
+	reader    *zip.ReadCloser
+	zipSource string
+	filter    *importFilter
+}
+
+func newZipImporter(zipFile string, config ImportConfig) (Importer, error) {
+	fmt.Fprintf(progressOutput(config.Output), "Loading files from zip archive: %s\n", zipFile)
+
+	// Open the zip file
+	reader, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ZIP file: %v", err)
+	}
+
+	filter, err := newZipImportFilter(reader, config)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	return &zipImporter{reader: reader, zipSource: filepath.Base(zipFile), filter: filter}, nil
+}
+
+// ImportZip imports all files from a zip archive without extracting them
+func ImportZip(ctx context.Context, c *client.Client, zipFile string, config ImportConfig) error {
+	return Import(ctx, c, "zip", zipFile, config)
+}
+
+func (i *zipImporter) Close() error {
+	return i.reader.Close()
+}
+
+// Namespace is zip:<archive name>, matching the zip_source metadata
+func (i *zipImporter) Namespace() string {
+	return "zip:" + i.zipSource
+}
+
+func (i *zipImporter) PruneFilter() map[string]interface{} {
+	return map[string]interface{}{
+		"source_type": "zip",
+		"zip_source":  i.zipSource,
+	}
+}
+
+func (i *zipImporter) Import(ctx context.Context, sink RecordSink) error {
+	// Process each file in the zip
+	for _, file := range i.reader.File {
+		// Skip directories
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		if file.Name == IgnoreFileName {
+			continue
+		}
+		if i.filter.Excluded(filepath.ToSlash(file.Name), false) {
+			sink.Skip(Record{Kind: "file", ExternalID: filepath.ToSlash(file.Name)}, "excluded")
+			continue
+		}
+
+		if err := sink.Add(zipFileRecord(file, i.zipSource)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newZipImportFilter returns the filter of a zip import, reading the .ragieignore
+// file at the root of the archive if there is one
+func newZipImportFilter(reader *zip.ReadCloser, config ImportConfig) (*importFilter, error) {
+	for _, file := range reader.File {
+		if file.Name != IgnoreFileName {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s in zip: %v", IgnoreFileName, err)
+		}
+		defer rc.Close()
+		return newImportFilter(config.Include, config.Exclude, rc)
+	}
+
+	return newImportFilter(config.Include, config.Exclude, nil)
+}
+
+// zipFileRecord returns the document of a file in a zip archive, which is
+// decompressed when it is hashed and again when it is uploaded
+func zipFileRecord(file *zip.File, zipSource string) Record {
+	// Generate a unique external ID based on the path within the zip
+	externalID := filepath.ToSlash(file.Name)
+
+	// Create metadata for the file
+	metadata := map[string]interface{}{
+		"source_type":     "zip",
+		"path":            externalID,
+		"extension":       filepath.Ext(file.Name),
+		"size":            file.UncompressedSize64,
+		"mod_time":        file.Modified.Format(time.RFC3339),
+		"compressed_size": file.CompressedSize64,
+		"zip_source":      zipSource,
+	}
+
+	return Record{
+		Kind:       "file",
+		ExternalID: externalID,
+		Name:       filepath.Base(file.Name),
+		Open: func() (io.ReadCloser, error) {
+			return &reopenReader{open: file.Open}, nil
+		},
+		FileName: file.Name,
+		Metadata: metadata,
+	}
+}
+
+// reopenReader reads a file that can be opened again but not seeked, such as a
+// compressed zip entry. Seeking back to the start reopens the file, which lets the
+// client resend it when retrying an upload.
+type reopenReader struct {
+	open func() (io.ReadCloser, error)
+	rc   io.ReadCloser
+	pos  int64
+}
+
+func (r *reopenReader) Read(p []byte) (int, error) {
+	if r.rc == nil {
+		rc, err := r.open()
+		if err != nil {
+			return 0, err
+		}
+		r.rc = rc
+	}
+
+	n, err := r.rc.Read(p)
+	r.pos += int64(n)
+	return n, err
+}
+
+// Seek only supports getting the current offset and seeking to the start
+func (r *reopenReader) Seek(offset int64, whence int) (int64, error) {
+	switch {
+	case offset == 0 && whence == io.SeekCurrent:
+		return r.pos, nil
+	case offset == 0 && whence == io.SeekStart:
+		err := r.Close()
+		r.pos = 0
+		return 0, err
+	default:
+		return r.pos, fmt.Errorf("unsupported seek to %d from %d", offset, whence)
+	}
+}
+
+func (r *reopenReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
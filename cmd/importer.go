@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"ragie/pkg/client"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Record is a single document read by an Importer. The shared import pipeline decides
+// whether it is created, replaced or skipped.
+type Record struct {
+	Kind       string // What the document is, e.g. "video" or "file", used in messages
+	ExternalID string
+	Name       string
+	Data       string // Text content, uploaded through the raw endpoint unless Open is set
+
+	// Open opens the file content, which is streamed as multipart form data. The
+	// content is opened when uploading, so files are never held in memory.
+	Open        func() (io.ReadCloser, error)
+	FileName    string
+	ContentHash string // Hash of the content, computed by the pipeline if empty
+	Metadata    map[string]interface{}
+}
+
+// RecordSink receives the records of an importer. Records are imported concurrently
+// with --concurrency, but their output is printed in the order they were added.
+type RecordSink interface {
+	// Add imports a record. It blocks while all workers are busy, and returns an
+	// error once the import was aborted, in which case the importer should stop.
+	Add(record Record) error
+	// Skip reports an item of the source that is not imported, and why
+	Skip(record Record, reason string)
+	// Fail reports an item of the source that could not be read. The returned error
+	// aborts the import with --fail-fast.
+	Fail(record Record, err error) error
+}
+
+// Importer reads an import source, such as a file or a directory, and sends its
+// documents to the shared pipeline, which handles existing documents, dry runs,
+// rate limits, checkpoints and reporting. An Importer that holds resources may
+// implement io.Closer.
+type Importer interface {
+	// Import sends each record of the source to sink in order
+	Import(ctx context.Context, sink RecordSink) error
+}
+
+// Pruner is implemented by importers that support --prune. Documents matching the
+// filter whose path metadata was not added during the import are deleted.
+type Pruner interface {
+	PruneFilter() map[string]interface{}
+}
+
+// Namespacer is implemented by importers whose external IDs are namespaced by more
+// than the name of the import type, such as "zip:docs.zip" for the files of an archive
+type Namespacer interface {
+	Namespace() string
+}
+
+// ImportSource is an import type, registered with RegisterImportSource and available
+// as a subcommand of import
+type ImportSource struct {
+	Name    string // Subcommand name, e.g. "files"
+	Short   string
+	Long    string
+	Example string
+
+	// Flags adds the flags of the source to its subcommand, if it has any
+	Flags func(flags *pflag.FlagSet)
+
+	// New returns the importer of the file or directory at path. It should fail early,
+	// before anything is imported, if the source cannot be read.
+	New func(path string, config ImportConfig) (Importer, error)
+}
+
+// importSources holds the registered import types by name
+var importSources = map[string]ImportSource{}
+
+// RegisterImportSource adds an import type and its import subcommand. It is meant to
+// be called from an init function.
+func RegisterImportSource(source ImportSource) {
+	if _, ok := importSources[source.Name]; ok {
+		panic(fmt.Sprintf("import source '%s' registered twice", source.Name))
+	}
+	importSources[source.Name] = source
+	importCmd.AddCommand(newImportSourceCmd(source))
+}
+
+// ImportSourceNames returns the names of the registered import types, sorted
+func ImportSourceNames() []string {
+	names := make([]string, 0, len(importSources))
+	for name := range importSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newImportSourceCmd(source ImportSource) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     source.Name + " <path>",
+		Short:   source.Short,
+		Long:    source.Long,
+		Example: source.Example,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := importConfigFromFlags()
+			if err := config.Validate(); err != nil {
+				return err
+			}
+			return Import(cmd.Context(), newClient(), source.Name, args[0], config)
+		},
+	}
+	if source.Flags != nil {
+		source.Flags(cmd.Flags())
+	}
+	return cmd
+}
+
+// Import imports the file or directory at path with the import type called source
+func Import(ctx context.Context, c *client.Client, source string, path string, config ImportConfig) error {
+	s, ok := importSources[source]
+	if !ok {
+		return fmt.Errorf("unknown import type: %s", source)
+	}
+
+	importer, err := s.New(path, config)
+	if err != nil {
+		return err
+	}
+	if closer, ok := importer.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// External IDs are namespaced by source, so that e.g. the same path imported from a
+	// directory and from an archive are different documents
+	if config.IDPrefix == "" {
+		config.IDPrefix = s.Name
+		if namespacer, ok := importer.(Namespacer); ok {
+			config.IDPrefix = namespacer.Namespace()
+		}
+	}
+
+	return runImport(ctx, c, importer, config)
+}
+
+// runImport sends the records of importer through the import pipeline
+func runImport(ctx context.Context, c *client.Client, importer Importer, config ImportConfig) error {
+	run, err := newImportRun(ctx, c, config)
+	if err != nil {
+		return err
+	}
+
+	if pruner, ok := importer.(Pruner); ok && config.Prune {
+		run.pruneFilter = pruner.PruneFilter()
+	}
+
+	importErr := importer.Import(run.ctx, run)
+	if err := run.Wait(); err != nil {
+		return err
+	}
+	return importErr
+}
+
+// Add implements RecordSink
+func (r *importRun) Add(record Record) error {
+	record.ExternalID = r.externalID(record.ExternalID)
+	if path, ok := record.Metadata["path"].(string); ok {
+		r.markSeen(path)
+	}
+
+	return r.Go(func(ctx context.Context, out io.Writer) error {
+		return r.importItem(ctx, record, out)
+	})
+}
+
+// Skip implements RecordSink
+func (r *importRun) Skip(record Record, reason string) {
+	record.ExternalID = r.externalID(record.ExternalID)
+	e := event{Action: actionSkipped, ExternalID: record.ExternalID, Name: record.Name, Message: reason}
+	r.Print(func(out io.Writer) {
+		r.report.Item(out, e, "skipping %s: %s\n", recordLabel(record), reason)
+	})
+}
+
+// Fail implements RecordSink
+func (r *importRun) Fail(record Record, err error) error {
+	record.ExternalID = r.externalID(record.ExternalID)
+	e := event{ExternalID: record.ExternalID, Name: record.Name}
+
+	var failErr error
+	r.Print(func(out io.Writer) {
+		failErr = r.failItem(out, e, err, "failed to read %s: %v\n", recordLabel(record), err)
+	})
+	if failErr != nil {
+		r.fail(failErr)
+	}
+	return failErr
+}
+
+// externalID returns the external ID of a record of the source, prefixed with the
+// namespace of the import, e.g. "files:docs:intro.md"
+func (r *importRun) externalID(id string) string {
+	if id == "" || r.config.IDPrefix == "" {
+		return id
+	}
+	return r.config.IDPrefix + ":" + id
+}
+
+// recordLabel describes a record in messages, e.g. "file docs/a.md"
+func recordLabel(record Record) string {
+	id := record.ExternalID
+	if id == "" {
+		id = record.Name
+	}
+
+	switch {
+	case record.Kind == "":
+		return id
+	case id == "":
+		return record.Kind
+	default:
+		return record.Kind + " " + id
+	}
+}
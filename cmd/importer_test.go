@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestImportSources(t *testing.T) {
+	expected := []string{"files", "readmeio", "wordpress", "youtube", "zip"}
+	if names := ImportSourceNames(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected import sources %v, got %v", expected, names)
+	}
+
+	for _, name := range expected {
+		cmd, _, err := importCmd.Find([]string{name})
+		if err != nil || cmd.Name() != name {
+			t.Errorf("Expected an import subcommand for %s, got %v", name, err)
+		}
+	}
+
+	api := newFakeAPI(t)
+	if err := Import(context.Background(), api.client(), "notion", "export.zip", ImportConfig{}); err == nil {
+		t.Errorf("Expected error for an unknown import type, but got none")
+	}
+}
+
+func TestImportRecordSink(t *testing.T) {
+	buf := captureStdout(t)
+	api := newFakeAPI(t)
+
+	path := filepath.Join(t.TempDir(), "videos.json")
+	videos := `[
+		{"videoId": "v1", "title": "First", "captions": ["Hello"]},
+		{"title": "No ID", "captions": ["Hello"]},
+		{"videoId": "v3", "title": "", "captions": [" "]},
+		{"videoId": "v4", "title": "Fourth", "captions": []}
+	]`
+	if err := os.WriteFile(path, []byte(videos), 0644); err != nil {
+		t.Fatalf("Failed to write videos: %v", err)
+	}
+
+	config := ImportConfig{Concurrency: 2, Output: OutputNDJSON}
+	if err := ImportYouTube(context.Background(), api.client(), path, config); err != nil {
+		t.Fatalf("ImportYouTube returned error: %v", err)
+	}
+
+	expected := []struct {
+		externalID string
+		action     string
+		message    string
+	}{
+		{"youtube:v1", actionCreated, ""},
+		{"", actionSkipped, "no videoId"},
+		{"youtube:v3", actionSkipped, "empty content"},
+		{"youtube:v4", actionCreated, ""},
+	}
+
+	var events []event
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var e event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Failed to parse line %q: %v", scanner.Text(), err)
+		}
+		if e.Type == "item" {
+			events = append(events, e)
+		}
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %s", len(expected), len(events), buf.String())
+	}
+	for i, e := range expected {
+		if events[i].ExternalID != e.externalID || events[i].Action != e.action || events[i].Message != e.message {
+			t.Errorf("Expected event %d to be %s %s (%s), got %+v", i, e.action, e.externalID, e.message, events[i])
+		}
+	}
+}
+
+func TestImportExternalIDNamespaces(t *testing.T) {
+	captureStdout(t)
+	api := newFakeAPI(t)
+
+	files := map[string]string{"docs/intro.md": "intro"}
+	dir := filepath.Join(t.TempDir(), "handbook")
+	writeTestFiles(t, dir, files)
+	zipPath := writeTestZip(t, files)
+
+	// The same path from a directory and from an archive are different documents
+	if err := ImportFiles(context.Background(), api.client(), dir, ImportConfig{}); err != nil {
+		t.Fatalf("Failed to import files: %v", err)
+	}
+	if err := ImportZip(context.Background(), api.client(), zipPath, ImportConfig{}); err != nil {
+		t.Fatalf("Failed to import zip: %v", err)
+	}
+	if err := ImportFiles(context.Background(), api.client(), dir, ImportConfig{IDPrefix: "kb"}); err != nil {
+		t.Fatalf("Failed to import files with an ID prefix: %v", err)
+	}
+
+	// A single file has the same external ID as in an import of its directory
+	if err := ImportFiles(context.Background(), api.client(), filepath.Join(dir, "docs", "intro.md"), ImportConfig{}); err != nil {
+		t.Fatalf("Failed to import a single file: %v", err)
+	}
+	if err := ImportFiles(context.Background(), api.client(), filepath.Join(dir, "docs"), ImportConfig{}); err != nil {
+		t.Fatalf("Failed to import a subdirectory: %v", err)
+	}
+
+	for _, externalID := range []string{"files:handbook:docs/intro.md", "zip:test.zip:docs/intro.md", "kb:docs/intro.md", "files:docs:intro.md"} {
+		if docs := api.documents(externalID); len(docs) != 1 {
+			t.Errorf("Expected 1 document for %s, got %d", externalID, len(docs))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"ragie/pkg/client"
+)
+
+// documentIndex maps external IDs to the documents of a partition. It is built once
+// before an import, rather than listing the documents of each item, and kept up to
+// date as documents are created and replaced.
+type documentIndex struct {
+	mu   sync.Mutex
+	docs map[string][]client.Document
+}
+
+// loadDocumentIndex lists all documents of partition. Its error must abort the import,
+// as every item would otherwise be considered new and uploaded again.
+func loadDocumentIndex(ctx context.Context, c *client.Client, partition string) (*documentIndex, error) {
+	docs, err := listDocuments(ctx, c, client.ListOptions{Partition: partition}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index existing documents: %w", err)
+	}
+
+	index := &documentIndex{docs: map[string][]client.Document{}}
+	for _, doc := range docs {
+		if externalID, ok := doc.Metadata["external_id"].(string); ok {
+			index.docs[externalID] = append(index.docs[externalID], doc)
+		}
+	}
+	return index, nil
+}
+
+// Len returns the number of documents in the index
+func (x *documentIndex) Len() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	n := 0
+	for _, docs := range x.docs {
+		n += len(docs)
+	}
+	return n
+}
+
+// Lookup returns the documents with the given external ID
+func (x *documentIndex) Lookup(externalID string) []client.Document {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return slices.Clone(x.docs[externalID])
+}
+
+// Add records a document created with the given external ID
+func (x *documentIndex) Add(externalID string, doc client.Document) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.docs[externalID] = append(x.docs[externalID], doc)
+}
+
+// Remove forgets a deleted document
+func (x *documentIndex) Remove(externalID string, docID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	docs := slices.DeleteFunc(x.docs[externalID], func(doc client.Document) bool {
+		return doc.ID == docID
+	})
+	if len(docs) == 0 {
+		delete(x.docs, externalID)
+	} else {
+		x.docs[externalID] = docs
+	}
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"ragie/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// MetadataUpdateConfig holds configuration for updating the metadata of documents
+type MetadataUpdateConfig struct {
+	DryRun    bool
+	Partition string
+	IDs       []string               // Documents to update, the documents matching Filter if empty
+	Filter    map[string]interface{} // Metadata filter of the documents to update
+	Set       map[string]interface{} // Metadata merged into the metadata of each document
+	Unset     []string               // Metadata keys removed from each document
+	Output    string
+	FailFast  bool
+
+	Concurrency int // Number of documents updated in parallel
+}
+
+var (
+	metadataFilter string
+	metadataWhere  []string
+	metadataJSON   string
+	metadataSet    []string
+	metadataUnset  []string
+)
+
+var documentsUpdateMetadataCmd = &cobra.Command{
+	Use:   "update-metadata [id]...",
+	Short: "Update the metadata of documents without processing them again",
+	Long: `Update the metadata of documents in place. Unlike importing again with --replace,
+the documents keep their ID and are not processed again, which is far cheaper for
+PDFs and videos.
+
+Selecting documents:
+  Pass the IDs of the documents to update, or select them with --filter and
+  --where as with 'documents list', e.g. --where source_type=zip.
+
+Changes:
+  --metadata takes a JSON object merged into the metadata, --set key=value sets a
+  single key, parsed like the value of --where, and --unset key removes a key.
+  Documents selected with a filter whose metadata already has the changes are
+  reported as unchanged.
+
+Documents are updated in parallel, 4 at a time unless --concurrency is set. Use
+--dry-run to list the documents that would be updated.`,
+	Example: `  ragie documents update-metadata doc-1 doc-2 --set team=search
+  ragie documents update-metadata --where source_type=files --set version=2 --unset draft
+  ragie documents update-metadata --where sourceType=wordpress --metadata '{"tags": ["blog"]}'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return err
+		}
+
+		filter, err := parseFilter(metadataFilter, metadataWhere)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && len(filter) > 0 {
+			return fmt.Errorf("pass either document IDs or --filter and --where, not both")
+		}
+		if len(args) == 0 && len(filter) == 0 {
+			return fmt.Errorf("select the documents to update with their IDs, --filter or --where")
+		}
+
+		set, err := parseMetadataChanges(metadataJSON, metadataSet)
+		if err != nil {
+			return err
+		}
+		if len(set) == 0 && len(metadataUnset) == 0 {
+			return fmt.Errorf("nothing to update, use --metadata, --set or --unset")
+		}
+
+		return UpdateMetadata(cmd.Context(), newClient(), MetadataUpdateConfig{
+			DryRun:    dryRun,
+			Partition: partition,
+			IDs:       args,
+			Filter:    filter,
+			Set:       set,
+			Unset:     metadataUnset,
+			Output:    outputFormat,
+			FailFast:  failFast,
+
+			Concurrency: batchConcurrency(),
+		})
+	},
+}
+
+func init() {
+	documentsCmd.AddCommand(documentsUpdateMetadataCmd)
+	flags := documentsUpdateMetadataCmd.Flags()
+	flags.StringVar(&metadataFilter, "filter", "", "Update the documents matching this metadata filter as JSON, e.g. '{\"source_type\": \"zip\"}'")
+	flags.StringArrayVar(&metadataWhere, "where", nil, "Update the documents whose metadata matches key=value (repeatable)")
+	flags.StringVar(&metadataJSON, "metadata", "", "Metadata to merge as a JSON object, e.g. '{\"tags\": [\"blog\"]}'")
+	flags.StringArrayVar(&metadataSet, "set", nil, "Metadata to set as key=value (repeatable)")
+	flags.StringArrayVar(&metadataUnset, "unset", nil, "Metadata key to remove (repeatable)")
+}
+
+// parseMetadataChanges combines a JSON object of metadata with key=value pairs
+func parseMetadataChanges(metadataJSON string, set []string) (map[string]interface{}, error) {
+	metadata := map[string]interface{}{}
+	if metadataJSON != "" {
+		if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+			return nil, fmt.Errorf("invalid --metadata, expected a JSON object: %v", err)
+		}
+	}
+
+	for _, pair := range set {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set '%s', expected key=value", pair)
+		}
+		metadata[key] = parseFilterValue(value)
+	}
+
+	return metadata, nil
+}
+
+// UpdateMetadata merges the metadata changes of config into the metadata of the
+// selected documents
+func UpdateMetadata(ctx context.Context, c *client.Client, config MetadataUpdateConfig) (err error) {
+	report, err := newReporter(config.Output, "update-metadata", config.DryRun)
+	if err != nil {
+		return err
+	}
+	defer func() { err = report.Finish(err) }()
+
+	patch := map[string]interface{}{}
+	for key, value := range config.Set {
+		patch[key] = value
+	}
+	for _, key := range config.Unset {
+		patch[key] = nil
+	}
+
+	// Documents given by ID are patched as is, as their metadata is not known
+	var docs []client.Document
+	if len(config.IDs) > 0 {
+		for _, id := range config.IDs {
+			docs = append(docs, client.Document{ID: id})
+		}
+	} else {
+		docs, err = listDocuments(ctx, c, client.ListOptions{Filter: config.Filter, Partition: config.Partition}, 0)
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			report.Infof("No documents to update\n")
+			return nil
+		}
+	}
+
+	pool := newImportPool(ctx, ImportConfig{Concurrency: config.Concurrency})
+	for _, doc := range docs {
+		if err := pool.Go(func(ctx context.Context, out io.Writer) error {
+			return updateDocumentMetadata(ctx, c, config, report, doc, patch, out)
+		}); err != nil {
+			break
+		}
+	}
+
+	return pool.Wait()
+}
+
+// updateDocumentMetadata patches the metadata of doc and reports the outcome. The
+// returned error aborts the update, on a fatal error or the first failure with
+// --fail-fast.
+func updateDocumentMetadata(ctx context.Context, c *client.Client, config MetadataUpdateConfig, report *reporter, doc client.Document, patch map[string]interface{}, out io.Writer) error {
+	start := time.Now()
+	e := event{Name: doc.Name, DocumentID: doc.ID}
+	if externalID, ok := doc.Metadata["external_id"].(string); ok {
+		e.ExternalID = externalID
+	}
+
+	changes := patch
+	if doc.Metadata != nil {
+		changes = metadataChanges(doc.Metadata, patch)
+		if len(changes) == 0 {
+			e.Action = actionUnchanged
+			report.Item(out, e, "unchanged %s\n", doc.ID)
+			return nil
+		}
+	}
+	e.Message = strings.Join(sortedKeys(changes), ", ")
+
+	if config.DryRun {
+		e.Action = actionMetadataUpdated
+		report.Item(out, e, "would update metadata of %s: %s\n", doc.ID, e.Message)
+		return nil
+	}
+
+	if _, err := c.PatchDocumentMetadataContext(ctx, doc.ID, changes); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.Action = actionFailed
+		e.Error = err.Error()
+		e.DurationMS = since(start)
+		if errors.Is(err, client.ErrUnauthorized) {
+			report.Item(out, e, "")
+			return fmt.Errorf("failed to update metadata of document %s: %w", doc.ID, err)
+		}
+		report.Item(out, e, "error updating metadata of document %s: %v\n", doc.ID, err)
+		if config.FailFast {
+			return errFailFast
+		}
+		return nil
+	}
+
+	e.Action = actionMetadataUpdated
+	e.DurationMS = since(start)
+	report.Item(out, e, "updated metadata of %s: %s\n", doc.ID, e.Message)
+	return nil
+}
+
+// metadataChanges returns the entries of patch that would change current. A nil value
+// removes the key, and values are compared by their JSON encoding, so that numbers
+// read back from the API as floats match.
+func metadataChanges(current map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	changes := map[string]interface{}{}
+	for key, value := range patch {
+		existing, ok := current[key]
+		if value == nil {
+			if ok {
+				changes[key] = nil
+			}
+			continue
+		}
+		if !ok || !jsonEqual(existing, value) {
+			changes[key] = value
+		}
+	}
+	return changes
+}
+
+// jsonEqual reports whether a and b have the same JSON encoding
+func jsonEqual(a, b interface{}) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
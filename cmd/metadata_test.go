@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMetadataChanges(t *testing.T) {
+	current := map[string]interface{}{
+		"source_type": "files",
+		"size":        float64(42),
+		"tags":        []interface{}{"a", "b"},
+		"draft":       true,
+	}
+
+	tests := []struct {
+		name     string
+		patch    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "same values",
+			patch:    map[string]interface{}{"source_type": "files", "size": int64(42), "tags": []string{"a", "b"}},
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "changed and new values",
+			patch:    map[string]interface{}{"size": 43, "title": "A"},
+			expected: map[string]interface{}{"size": 43, "title": "A"},
+		},
+		{
+			name:     "removed keys",
+			patch:    map[string]interface{}{"draft": nil, "missing": nil},
+			expected: map[string]interface{}{"draft": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := metadataChanges(current, tt.patch)
+			if !reflect.DeepEqual(changes, tt.expected) {
+				t.Errorf("Expected changes %v, got %v", tt.expected, changes)
+			}
+		})
+	}
+}
+
+func TestParseMetadataChanges(t *testing.T) {
+	metadata, err := parseMetadataChanges(`{"tags": ["blog"], "team": "docs"}`, []string{"team=search", "version=2", "code=\"007\""})
+	if err != nil {
+		t.Fatalf("parseMetadataChanges returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"tags":    []interface{}{"blog"},
+		"team":    "search",
+		"version": float64(2),
+		"code":    "007",
+	}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Expected metadata %v, got %v", expected, metadata)
+	}
+
+	if _, err := parseMetadataChanges("", []string{"team"}); err == nil {
+		t.Errorf("Expected error for --set without a value, but got none")
+	}
+	if _, err := parseMetadataChanges("[1]", nil); err == nil {
+		t.Errorf("Expected error for --metadata that is not an object, but got none")
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      MetadataUpdateConfig
+		expectError bool
+		expected    map[string]map[string]interface{} // Metadata of each document once done
+	}{
+		{
+			name: "filter",
+			config: MetadataUpdateConfig{
+				Filter: map[string]interface{}{"source_type": "zip"},
+				Set:    map[string]interface{}{"team": "search"},
+				Unset:  []string{"draft"},
+			},
+			expected: map[string]map[string]interface{}{
+				"doc-1": {"source_type": "files", "draft": true},
+				"doc-2": {"source_type": "zip", "team": "search"},
+				"doc-3": {"source_type": "zip", "team": "search"},
+			},
+		},
+		{
+			name:   "ids",
+			config: MetadataUpdateConfig{IDs: []string{"doc-1"}, Set: map[string]interface{}{"team": "search"}},
+			expected: map[string]map[string]interface{}{
+				"doc-1": {"source_type": "files", "draft": true, "team": "search"},
+				"doc-2": {"source_type": "zip", "draft": true},
+				"doc-3": {"source_type": "zip", "team": "search"},
+			},
+		},
+		{
+			name:        "missing document",
+			config:      MetadataUpdateConfig{IDs: []string{"doc-1", "doc-9"}, Set: map[string]interface{}{"team": "search"}},
+			expectError: true,
+			expected: map[string]map[string]interface{}{
+				"doc-1": {"source_type": "files", "draft": true, "team": "search"},
+				"doc-2": {"source_type": "zip", "draft": true},
+				"doc-3": {"source_type": "zip", "team": "search"},
+			},
+		},
+		{
+			name: "dry run",
+			config: MetadataUpdateConfig{
+				DryRun: true,
+				Filter: map[string]interface{}{"source_type": "zip"},
+				Set:    map[string]interface{}{"team": "search"},
+			},
+			expected: map[string]map[string]interface{}{
+				"doc-1": {"source_type": "files", "draft": true},
+				"doc-2": {"source_type": "zip", "draft": true},
+				"doc-3": {"source_type": "zip", "team": "search"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureStdout(t)
+			api := newFakeAPI(t)
+			api.add("", "a.txt", map[string]interface{}{"source_type": "files", "draft": true})
+			api.add("", "b.txt", map[string]interface{}{"source_type": "zip", "draft": true})
+			api.add("", "c.txt", map[string]interface{}{"source_type": "zip", "team": "search"})
+
+			tt.config.Concurrency = 2
+			err := UpdateMetadata(context.Background(), api.client(), tt.config)
+
+			var itemsFailed *ItemsFailedError
+			if tt.expectError != errors.As(err, &itemsFailed) {
+				t.Fatalf("Expected failed items %v, got %v", tt.expectError, err)
+			}
+
+			for _, doc := range api.docs {
+				if !reflect.DeepEqual(doc.Metadata, tt.expected[doc.ID]) {
+					t.Errorf("Expected metadata of %s to be %v, got %v", doc.ID, tt.expected[doc.ID], doc.Metadata)
+				}
+			}
+
+			// Documents that already have the changes are not patched
+			if tt.name == "filter" {
+				if n := api.count("PATCH "); n != 1 {
+					t.Errorf("Expected 1 metadata update, got %d", n)
+				}
+			}
+		})
+	}
+}
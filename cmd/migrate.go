@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"ragie/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// MigrateConfig holds configuration for migrating the external IDs of documents
+type MigrateConfig struct {
+	DryRun    bool
+	Partition string
+	Filter    map[string]interface{} // Metadata filter of the documents to migrate, all documents if empty
+	Source    string                 // Import type of the documents without source metadata, e.g. "youtube"
+	IDPrefix  string                 // Namespace to use instead of the import type's, as with import --id-prefix
+	Output    string
+	FailFast  bool
+
+	Concurrency int // Number of documents updated in parallel
+}
+
+var (
+	migrateFilter string
+	migrateWhere  []string
+	migrateSource string
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate documents imported by earlier versions",
+	Long:  `Update documents imported by earlier versions to what imports now create.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var migrateExternalIDsCmd = &cobra.Command{
+	Use:   "external-ids",
+	Short: "Namespace the external IDs of documents by their source",
+	Long: `Rewrite the bare external IDs of documents imported by earlier versions to the
+namespaced external IDs imports now use, e.g. docs/intro.md imported from
+docs.zip becomes zip:docs.zip:docs/intro.md. Only the external_id metadata is
+updated, the documents keep their ID and are not processed again. Run it before
+importing again, or the import would not find the existing documents.
+
+Sources:
+  The import type of each document is found from its metadata: source_type for
+  'files' and 'zip', sourceType for 'readmeio' and 'wordpress'. YouTube imports
+  have no such metadata, so select them with --source youtube, which also
+  migrates the selected documents without source metadata.
+
+  The directory of a 'files' import is not in its metadata either. Select its
+  documents and pass the namespace the import now uses, e.g.
+    ragie migrate external-ids --where source_type=files --id-prefix files:docs
+  --id-prefix also migrates documents that are imported with --id-prefix.
+
+Documents that already have the new external ID are reported as unchanged, and
+documents whose source is unknown are skipped. Use --dry-run to list the changes.`,
+	Example: `  ragie migrate external-ids --dry-run
+  ragie migrate external-ids --where source_type=files --id-prefix files:docs
+  ragie migrate external-ids --source youtube`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return err
+		}
+
+		filter, err := parseFilter(migrateFilter, migrateWhere)
+		if err != nil {
+			return err
+		}
+
+		if migrateSource != "" {
+			if _, ok := importSources[migrateSource]; !ok {
+				return fmt.Errorf("unknown --source '%s', expected one of %s", migrateSource, strings.Join(ImportSourceNames(), ", "))
+			}
+		}
+		if migrateSource == "files" && idPrefix == "" {
+			return fmt.Errorf("--source files requires --id-prefix, e.g. files:<directory name>")
+		}
+		// The same namespace is given to every selected document, whatever its source
+		if idPrefix != "" && migrateSource == "" && len(filter) == 0 {
+			return fmt.Errorf("--id-prefix requires selecting the documents with --source, --filter or --where")
+		}
+
+		return MigrateExternalIDs(cmd.Context(), newClient(), MigrateConfig{
+			DryRun:    dryRun,
+			Partition: partition,
+			Filter:    filter,
+			Source:    migrateSource,
+			IDPrefix:  idPrefix,
+			Output:    outputFormat,
+			FailFast:  failFast,
+
+			Concurrency: batchConcurrency(),
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateExternalIDsCmd)
+	flags := migrateExternalIDsCmd.Flags()
+	flags.StringVar(&migrateFilter, "filter", "", "Only migrate documents matching this metadata filter as JSON, e.g. '{\"source_type\": \"zip\"}'")
+	flags.StringArrayVar(&migrateWhere, "where", nil, "Only migrate documents whose metadata matches key=value (repeatable)")
+	flags.StringVar(&migrateSource, "source", "", "Import type of the documents without source metadata, e.g. 'youtube'")
+	flags.StringVar(&idPrefix, "id-prefix", "", "Namespace of the new external IDs, required for 'files' documents, e.g. 'files:docs'")
+}
+
+// MigrateExternalIDs prefixes the bare external IDs of the selected documents with the
+// namespace of their source
+func MigrateExternalIDs(ctx context.Context, c *client.Client, config MigrateConfig) (err error) {
+	report, err := newReporter(config.Output, "migrate", config.DryRun)
+	if err != nil {
+		return err
+	}
+	defer func() { err = report.Finish(err) }()
+
+	docs, err := listDocuments(ctx, c, client.ListOptions{Filter: config.Filter, Partition: config.Partition}, 0)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		report.Infof("No documents to migrate\n")
+		return nil
+	}
+
+	pool := newImportPool(ctx, ImportConfig{Concurrency: config.Concurrency})
+	for _, doc := range docs {
+		if err := pool.Go(func(ctx context.Context, out io.Writer) error {
+			return migrateExternalID(ctx, c, config, report, doc, out)
+		}); err != nil {
+			break
+		}
+	}
+
+	return pool.Wait()
+}
+
+// migrateExternalID updates the external ID of doc and reports the outcome. The
+// returned error aborts the migration, on a fatal error or the first failure with
+// --fail-fast.
+func migrateExternalID(ctx context.Context, c *client.Client, config MigrateConfig, report *reporter, doc client.Document, out io.Writer) error {
+	start := time.Now()
+	externalID, _ := doc.Metadata["external_id"].(string)
+	e := event{Name: doc.Name, DocumentID: doc.ID, ExternalID: externalID}
+
+	if externalID == "" {
+		e.Action = actionSkipped
+		e.Message = "no external ID"
+		report.Item(out, e, "skipping %s: no external ID\n", doc.ID)
+		return nil
+	}
+
+	namespace, err := externalIDNamespace(doc.Metadata, config)
+	if err != nil {
+		e.Action = actionSkipped
+		e.Message = err.Error()
+		report.Item(out, e, "skipping %s: %v\n", doc.ID, err)
+		return nil
+	}
+
+	if strings.HasPrefix(externalID, namespace+":") {
+		e.Action = actionUnchanged
+		report.Item(out, e, "unchanged %s: %s\n", doc.ID, externalID)
+		return nil
+	}
+
+	e.ExternalID = namespace + ":" + externalID
+	e.Message = "from " + externalID
+
+	if config.DryRun {
+		e.Action = actionMigrated
+		report.Item(out, e, "would migrate %s: %s -> %s\n", doc.ID, externalID, e.ExternalID)
+		return nil
+	}
+
+	if _, err := c.PatchDocumentMetadataContext(ctx, doc.ID, map[string]interface{}{"external_id": e.ExternalID}); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.Action = actionFailed
+		e.Error = err.Error()
+		e.DurationMS = since(start)
+		if errors.Is(err, client.ErrUnauthorized) {
+			report.Item(out, e, "")
+			return fmt.Errorf("failed to migrate document %s: %w", doc.ID, err)
+		}
+		report.Item(out, e, "error migrating document %s: %v\n", doc.ID, err)
+		if config.FailFast {
+			return errFailFast
+		}
+		return nil
+	}
+
+	e.Action = actionMigrated
+	e.DurationMS = since(start)
+	report.Item(out, e, "migrated %s: %s -> %s\n", doc.ID, externalID, e.ExternalID)
+	return nil
+}
+
+// externalIDNamespace returns the namespace an import now gives the document with the
+// given metadata, or an error explaining why it is unknown
+func externalIDNamespace(metadata map[string]interface{}, config MigrateConfig) (string, error) {
+	source := documentSource(metadata)
+	if config.Source != "" {
+		if source != "" && source != config.Source {
+			return "", fmt.Errorf("imported with %s, not %s", source, config.Source)
+		}
+		source = config.Source
+	}
+
+	if config.IDPrefix != "" {
+		return config.IDPrefix, nil
+	}
+
+	switch source {
+	case "":
+		return "", fmt.Errorf("unknown source, select the documents with --source")
+	case "files":
+		return "", fmt.Errorf("unknown directory, pass --id-prefix files:<directory name>")
+	case "zip":
+		zipSource, _ := metadata["zip_source"].(string)
+		if zipSource == "" {
+			return "", fmt.Errorf("no zip_source metadata")
+		}
+		return "zip:" + zipSource, nil
+	default:
+		return source, nil
+	}
+}
+
+// documentSource returns the import type of a document from its source_type or
+// sourceType metadata, or "" if it has neither, like YouTube imports
+func documentSource(metadata map[string]interface{}) string {
+	if source, ok := metadata["source_type"].(string); ok {
+		return source
+	}
+	source, _ := metadata["sourceType"].(string)
+	return source
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExternalIDNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    map[string]interface{}
+		config      MigrateConfig
+		expected    string
+		expectError bool
+	}{
+		{name: "zip", metadata: map[string]interface{}{"source_type": "zip", "zip_source": "docs.zip"}, expected: "zip:docs.zip"},
+		{name: "zip without archive", metadata: map[string]interface{}{"source_type": "zip"}, expectError: true},
+		{name: "readmeio", metadata: map[string]interface{}{"sourceType": "readmeio"}, expected: "readmeio"},
+		{name: "wordpress", metadata: map[string]interface{}{"sourceType": "wordpress"}, expected: "wordpress"},
+		{name: "files without prefix", metadata: map[string]interface{}{"source_type": "files"}, expectError: true},
+		{name: "files with prefix", metadata: map[string]interface{}{"source_type": "files"}, config: MigrateConfig{IDPrefix: "files:docs"}, expected: "files:docs"},
+		{name: "unknown source", metadata: map[string]interface{}{"title": "Video"}, expectError: true},
+		{name: "given source", metadata: map[string]interface{}{"title": "Video"}, config: MigrateConfig{Source: "youtube"}, expected: "youtube"},
+		{name: "other source", metadata: map[string]interface{}{"source_type": "zip"}, config: MigrateConfig{Source: "youtube"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace, err := externalIDNamespace(tt.metadata, tt.config)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got namespace '%s'", namespace)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if namespace != tt.expected {
+				t.Errorf("Expected namespace '%s', got '%s'", tt.expected, namespace)
+			}
+		})
+	}
+}
+
+func TestMigrateExternalIDs(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      MigrateConfig
+		failPatch   bool
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name: "migrate",
+			expected: map[string]string{
+				"doc-1": "zip:docs.zip:intro.md",
+				"doc-2": "readmeio:getting-started",
+				"doc-3": "v1",
+				"doc-4": "zip:docs.zip:guide.md",
+			},
+		},
+		{
+			name:   "source",
+			config: MigrateConfig{Source: "youtube"},
+			expected: map[string]string{
+				"doc-1": "intro.md",
+				"doc-2": "getting-started",
+				"doc-3": "youtube:v1",
+				"doc-4": "zip:docs.zip:guide.md",
+			},
+		},
+		{
+			name:   "dry run",
+			config: MigrateConfig{DryRun: true},
+			expected: map[string]string{
+				"doc-1": "intro.md",
+				"doc-2": "getting-started",
+				"doc-3": "v1",
+				"doc-4": "zip:docs.zip:guide.md",
+			},
+		},
+		{
+			name:        "failed",
+			failPatch:   true,
+			expectError: true,
+			expected: map[string]string{
+				"doc-1": "intro.md",
+				"doc-2": "getting-started",
+				"doc-3": "v1",
+				"doc-4": "zip:docs.zip:guide.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureStdout(t)
+			api := newFakeAPI(t)
+			api.add("", "intro.md", map[string]interface{}{"external_id": "intro.md", "source_type": "zip", "zip_source": "docs.zip"})
+			api.add("", "Getting Started", map[string]interface{}{"external_id": "getting-started", "sourceType": "readmeio"})
+			api.add("", "Video", map[string]interface{}{"external_id": "v1", "title": "Video"})
+			api.add("", "guide.md", map[string]interface{}{"external_id": "zip:docs.zip:guide.md", "source_type": "zip", "zip_source": "docs.zip"})
+			if tt.failPatch {
+				api.failUploads("intro.md")
+				api.failUploads("Getting Started")
+			}
+
+			tt.config.Concurrency = 2
+			err := MigrateExternalIDs(context.Background(), api.client(), tt.config)
+
+			var itemsFailed *ItemsFailedError
+			if tt.expectError != errors.As(err, &itemsFailed) {
+				t.Fatalf("Expected failed items %v, got %v", tt.expectError, err)
+			}
+
+			for _, doc := range api.docs {
+				if doc.Metadata["external_id"] != tt.expected[doc.ID] {
+					t.Errorf("Expected external ID of %s to be %s, got %v", doc.ID, tt.expected[doc.ID], doc.Metadata["external_id"])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output formats accepted by --output
+const (
+	OutputText   = "text"
+	OutputJSON   = "json"
+	OutputNDJSON = "ndjson"
+)
+
+// Actions reported for each item
+const (
+	actionCreated   = "created"
+	actionReplaced  = "replaced"
+	actionUpdated   = "updated"
+	actionUnchanged = "unchanged"
+	actionSkipped   = "skipped"
+	actionDeleted   = "deleted"
+	actionPruned    = "pruned"
+	actionFailed    = "failed"
+
+	// A document whose metadata was updated without changing its content, with
+	// import --update-metadata or documents update-metadata
+	actionMetadataUpdated = "metadata_updated"
+
+	// A document whose external ID was rewritten by migrate external-ids
+	actionMigrated = "migrated"
+
+	// A document that was created but failed processing, only checked with
+	// import --wait. The item was already counted when it was created.
+	actionProcessingFailed = "processing_failed"
+)
+
+// ExitItemsFailed is the exit code of a command that completed but failed some items
+const ExitItemsFailed = 2
+
+// errFailFast aborts a command on the first failed item with --fail-fast
+var errFailFast = errors.New("aborted on the first failed item (--fail-fast)")
+
+// ItemsFailedError is returned by a command whose items failed, after all the other
+// items were processed, or on the first failure with --fail-fast
+type ItemsFailedError struct {
+	Command  string
+	Failed   int
+	Total    int
+	FailFast bool
+}
+
+func (e *ItemsFailedError) Error() string {
+	if e.FailFast {
+		return fmt.Sprintf("%s aborted after a failed item (--fail-fast)", e.Command)
+	}
+	return fmt.Sprintf("%s failed for %d of %d items", e.Command, e.Failed, e.Total)
+}
+
+// stdout is where command output is written, replaced in tests
+var stdout io.Writer = os.Stdout
+
+// event is the outcome of a single item of a command
+type event struct {
+	Type                string   `json:"type"`
+	Command             string   `json:"command"`
+	Action              string   `json:"action"`
+	ExternalID          string   `json:"external_id,omitempty"`
+	Name                string   `json:"name,omitempty"`
+	DocumentID          string   `json:"document_id,omitempty"`
+	ReplacedDocumentIDs []string `json:"replaced_document_ids,omitempty"`
+	Message             string   `json:"message,omitempty"`
+	Error               string   `json:"error,omitempty"`
+	DryRun              bool     `json:"dry_run,omitempty"`
+	DurationMS          int64    `json:"duration_ms"`
+}
+
+// summary is the final record of a command, counting its items by action
+type summary struct {
+	Type       string         `json:"type"`
+	Command    string         `json:"command"`
+	Counts     map[string]int `json:"counts"`
+	Total      int            `json:"total"`
+	Error      string         `json:"error,omitempty"`
+	DryRun     bool           `json:"dry_run,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+}
+
+// reporter writes the outcome of each item in the selected output format. The text
+// format prints the same messages as always, ndjson streams one JSON event per line
+// followed by a summary line, and json prints a single document with all events and
+// the summary once the command is done.
+type reporter struct {
+	format  string
+	command string
+	dryRun  bool
+	start   time.Time
+
+	mu     sync.Mutex
+	counts map[string]int
+	events []event
+}
+
+func newReporter(format string, command string, dryRun bool) (*reporter, error) {
+	if err := validateOutputFormat(format); err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = OutputText
+	}
+
+	return &reporter{
+		format:  format,
+		command: command,
+		dryRun:  dryRun,
+		start:   time.Now(),
+		counts:  map[string]int{},
+		events:  []event{},
+	}, nil
+}
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case "", OutputText, OutputJSON, OutputNDJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format '%s', must be one of: text, json, ndjson", format)
+	}
+}
+
+// progressOutput returns where progress messages such as "Loading file" are written.
+// They go to stderr with a machine readable format so that stdout can be parsed.
+func progressOutput(format string) io.Writer {
+	if format == OutputJSON || format == OutputNDJSON {
+		return os.Stderr
+	}
+	return stdout
+}
+
+// Infof prints a progress message
+func (r *reporter) Infof(format string, args ...interface{}) {
+	fmt.Fprintf(progressOutput(r.format), format, args...)
+}
+
+// Textf writes a message to out with the text format only, for details that are
+// part of an item's event in the other formats
+func (r *reporter) Textf(out io.Writer, format string, args ...interface{}) {
+	if r.format == OutputText {
+		fmt.Fprintf(out, format, args...)
+	}
+}
+
+// Item reports the outcome of an item. With the text format the message is written
+// to out, with ndjson the event is.
+func (r *reporter) Item(out io.Writer, e event, format string, args ...interface{}) {
+	e.Type = "item"
+	e.Command = r.command
+	e.DryRun = r.dryRun
+
+	r.mu.Lock()
+	r.counts[e.Action]++
+	if r.format == OutputJSON {
+		r.events = append(r.events, e)
+	}
+	r.mu.Unlock()
+
+	switch r.format {
+	case OutputText:
+		fmt.Fprintf(out, format, args...)
+	case OutputNDJSON:
+		line, _ := json.Marshal(e)
+		fmt.Fprintf(out, "%s\n", line)
+	}
+}
+
+// Count returns the number of items reported with action
+func (r *reporter) Count(action string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[action]
+}
+
+// Finish writes the summary and returns the error of the command: err if it was
+// aborted, or an *ItemsFailedError if any item failed
+func (r *reporter) Finish(err error) error {
+	r.mu.Lock()
+	failed, total := r.counts[actionFailed]+r.counts[actionProcessingFailed], r.total()
+	r.mu.Unlock()
+
+	if failed > 0 && (err == nil || errors.Is(err, errFailFast)) {
+		err = &ItemsFailedError{
+			Command:  r.command,
+			Failed:   failed,
+			Total:    total,
+			FailFast: err != nil,
+		}
+	}
+
+	r.Summary(err)
+	return err
+}
+
+// Summary writes the summary, or with the json format the whole document. err is the
+// error that aborted the command, if any.
+func (r *reporter) Summary(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := summary{
+		Type:       "summary",
+		Command:    r.command,
+		Counts:     r.counts,
+		Total:      r.total(),
+		DryRun:     r.dryRun,
+		DurationMS: time.Since(r.start).Milliseconds(),
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+
+	switch r.format {
+	case OutputText:
+		fmt.Fprintln(stdout, r.summaryText())
+	case OutputNDJSON:
+		line, _ := json.Marshal(s)
+		fmt.Fprintf(stdout, "%s\n", line)
+	case OutputJSON:
+		doc, _ := json.MarshalIndent(struct {
+			Events  []event `json:"events"`
+			Summary summary `json:"summary"`
+		}{r.events, s}, "", "  ")
+		fmt.Fprintf(stdout, "%s\n", doc)
+	}
+}
+
+// summaryActions is the order in which the counts are listed in the text summary
+var summaryActions = []string{actionCreated, actionReplaced, actionUpdated, actionMetadataUpdated, actionMigrated, actionUnchanged, actionDeleted, actionPruned, actionSkipped}
+
+// summaryText returns e.g. "import: 2 created, 1 skipped, 0 failed in 1.2s". The
+// failed count is always listed, the others only when non-zero.
+func (r *reporter) summaryText() string {
+	var counts []string
+	for _, action := range summaryActions {
+		if n := r.counts[action]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, strings.ReplaceAll(action, "_", " ")))
+		}
+	}
+	counts = append(counts, fmt.Sprintf("%d %s", r.counts[actionFailed], actionFailed))
+	if n := r.counts[actionProcessingFailed]; n > 0 {
+		counts = append(counts, fmt.Sprintf("%d failed processing", n))
+	}
+
+	command := r.command
+	if r.dryRun {
+		command += " (dry run)"
+	}
+	return fmt.Sprintf("%s: %s in %v", command, strings.Join(counts, ", "), time.Since(r.start).Round(time.Millisecond))
+}
+
+// total returns the number of items, the caller must hold r.mu
+func (r *reporter) total() int {
+	total := 0
+	for action, n := range r.counts {
+		if action != actionProcessingFailed {
+			total += n
+		}
+	}
+	return total
+}
+
+// since returns the milliseconds elapsed since start, for an event's duration
+func since(start time.Time) int64 {
+	return time.Since(start).Milliseconds()
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// captureStdout redirects command output to a buffer for the duration of the test
+func captureStdout(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := stdout
+	stdout = &buf
+	t.Cleanup(func() { stdout = previous })
+	return &buf
+}
+
+func TestReporterFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{name: "text", format: OutputText, expected: "saved: doc-1\nonly in text\nwarning: skipping empty file: b.txt\n"},
+		{name: "default", format: "", expected: "saved: doc-1\nonly in text\nwarning: skipping empty file: b.txt\n"},
+		{name: "ndjson", format: OutputNDJSON, expected: `{"type":"item","command":"import","action":"created","external_id":"a.txt","document_id":"doc-1","duration_ms":0}` + "\n" +
+			`{"type":"item","command":"import","action":"skipped","external_id":"b.txt","message":"empty file","duration_ms":0}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newReporter(tt.format, "import", false)
+			if err != nil {
+				t.Fatalf("Failed to create reporter: %v", err)
+			}
+
+			var out bytes.Buffer
+			r.Item(&out, event{Action: actionCreated, ExternalID: "a.txt", DocumentID: "doc-1"}, "saved: %s\n", "doc-1")
+			r.Textf(&out, "only in text\n")
+			r.Item(&out, event{Action: actionSkipped, ExternalID: "b.txt", Message: "empty file"}, "warning: skipping empty file: %s\n", "b.txt")
+
+			if out.String() != tt.expected {
+				t.Errorf("Expected output %q, got %q", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestReporterJSONSummary(t *testing.T) {
+	buf := captureStdout(t)
+
+	r, err := newReporter(OutputJSON, "clear", true)
+	if err != nil {
+		t.Fatalf("Failed to create reporter: %v", err)
+	}
+
+	var out bytes.Buffer
+	r.Item(&out, event{Action: actionDeleted, DocumentID: "doc-1"}, "would delete %s\n", "doc-1")
+	r.Item(&out, event{Action: actionDeleted, DocumentID: "doc-2"}, "would delete %s\n", "doc-2")
+	r.Item(&out, event{Action: actionFailed, DocumentID: "doc-3", Error: "boom"}, "error deleting document: boom\n")
+	if out.Len() != 0 {
+		t.Errorf("Expected no output before the summary, got %q", out.String())
+	}
+	r.Summary(errors.New("aborted"))
+
+	var doc struct {
+		Events  []event `json:"events"`
+		Summary summary `json:"summary"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", buf.String(), err)
+	}
+
+	if len(doc.Events) != 3 || !doc.Events[0].DryRun || doc.Events[0].Command != "clear" {
+		t.Errorf("Expected 3 dry run clear events, got %+v", doc.Events)
+	}
+	if doc.Summary.Total != 3 || doc.Summary.Counts[actionDeleted] != 2 || doc.Summary.Counts[actionFailed] != 1 {
+		t.Errorf("Expected 2 deleted and 1 failed, got %+v", doc.Summary)
+	}
+	if doc.Summary.Error != "aborted" {
+		t.Errorf("Expected summary error 'aborted', got '%s'", doc.Summary.Error)
+	}
+}
+
+func TestReporterInvalidFormat(t *testing.T) {
+	if _, err := newReporter("yaml", "import", false); err == nil {
+		t.Errorf("Expected error for invalid output format")
+	}
+	if err := (ImportConfig{Output: "yaml"}).Validate(); err == nil {
+		t.Errorf("Expected error for invalid output format in import config")
+	}
+}
+
+func TestImportFilesNDJSON(t *testing.T) {
+	buf := captureStdout(t)
+	api := newFakeAPI(t)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a.txt":     "a",
+		"b.txt":     "b",
+		"empty.txt": " ",
+	})
+	existingID := api.add("", "b.txt", map[string]interface{}{"external_id": filesID(dir, "b.txt")})
+
+	config := ImportConfig{Replace: true, Concurrency: 2, Output: OutputNDJSON}
+	if err := ImportFiles(context.Background(), api.client(), dir, config); err != nil {
+		t.Fatalf("Failed to import files: %v", err)
+	}
+
+	var events []event
+	var last summary
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var e event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Failed to parse line %q: %v", scanner.Text(), err)
+		}
+		if e.Type == "summary" {
+			json.Unmarshal(scanner.Bytes(), &last)
+			continue
+		}
+		events = append(events, e)
+	}
+
+	expected := []struct {
+		externalID string
+		action     string
+	}{
+		{filesID(dir, "a.txt"), actionCreated},
+		{filesID(dir, "b.txt"), actionReplaced},
+		{filesID(dir, "empty.txt"), actionSkipped},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %s", len(expected), len(events), buf.String())
+	}
+	for i, e := range expected {
+		if events[i].ExternalID != e.externalID || events[i].Action != e.action {
+			t.Errorf("Expected event %d to be %s %s, got %s %s", i, e.action, e.externalID, events[i].Action, events[i].ExternalID)
+		}
+	}
+	if events[0].DocumentID == "" {
+		t.Errorf("Expected document ID for created event")
+	}
+	if len(events[1].ReplacedDocumentIDs) != 1 || events[1].ReplacedDocumentIDs[0] != existingID {
+		t.Errorf("Expected %s to be replaced, got %v", existingID, events[1].ReplacedDocumentIDs)
+	}
+
+	if last.Total != 3 || last.Counts[actionCreated] != 1 || last.Counts[actionReplaced] != 1 {
+		t.Errorf("Unexpected summary: %+v", last)
+	}
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"ragie/pkg/client"
+)
+
+// importTask processes a single item, writing its progress messages to out.
+// A returned error aborts the whole import.
+type importTask func(ctx context.Context, out io.Writer) error
+
+// importPool runs import tasks on a bounded number of workers. Each task writes to
+// its own buffer which is flushed in submission order, so the output of concurrent
+// tasks never interleaves and reads the same as a sequential run.
+type importPool struct {
+	parent  context.Context
+	ctx     context.Context
+	cancel  context.CancelFunc
+	out     io.Writer
+	limiter *client.RateLimiter
+	slots   chan struct{}
+	queue   chan *queuedTask
+	flushed chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+type queuedTask struct {
+	out  bytes.Buffer
+	done chan struct{}
+}
+
+func newImportPool(ctx context.Context, config ImportConfig) *importPool {
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	poolCtx, cancel := context.WithCancel(ctx)
+	p := &importPool{
+		parent:  ctx,
+		ctx:     poolCtx,
+		cancel:  cancel,
+		out:     stdout,
+		slots:   make(chan struct{}, concurrency),
+		queue:   make(chan *queuedTask, concurrency),
+		flushed: make(chan struct{}),
+	}
+
+	// The legacy per-item delay paces the start of each task
+	if config.Delay > 0 {
+		p.limiter = client.NewRateLimiter(1/config.Delay, 1)
+	}
+
+	go p.flush()
+	return p
+}
+
+// Go schedules task, blocking while all workers are busy. It returns the reason the
+// pool stopped, if it has, in which case the task is not run and the caller should
+// stop submitting.
+func (p *importPool) Go(task importTask) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.ctx.Done():
+		return p.stopErr()
+	}
+
+	if err := p.limiter.Wait(p.ctx); err != nil {
+		<-p.slots
+		return p.stopErr()
+	}
+
+	t := &queuedTask{done: make(chan struct{})}
+	p.queue <- t
+
+	go func() {
+		defer func() {
+			<-p.slots
+			close(t.done)
+		}()
+
+		if p.ctx.Err() != nil {
+			return
+		}
+		if err := task(p.ctx, &t.out); err != nil {
+			p.fail(err)
+		}
+	}()
+
+	return nil
+}
+
+// Wait waits for all scheduled tasks, flushes their output and returns the first
+// error that aborted the import, if any
+func (p *importPool) Wait() error {
+	close(p.queue)
+	<-p.flushed
+	p.cancel()
+	return p.stopErr()
+}
+
+func (p *importPool) flush() {
+	defer close(p.flushed)
+	for t := range p.queue {
+		<-t.done
+		io.Copy(p.out, &t.out)
+	}
+}
+
+func (p *importPool) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+		p.cancel()
+	}
+}
+
+func (p *importPool) stopErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	return p.parent.Err()
+}
+
+// Print queues the output written by write, which is printed in order with the
+// output of the tasks
+func (p *importPool) Print(write func(out io.Writer)) {
+	t := &queuedTask{done: make(chan struct{})}
+	write(&t.out)
+	close(t.done)
+	p.queue <- t
+}
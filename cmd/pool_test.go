@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestImportPoolOrderedOutput(t *testing.T) {
+	pool := newImportPool(context.Background(), ImportConfig{Concurrency: 4})
+	var out bytes.Buffer
+	pool.out = &out
+
+	var running, maxRunning int32
+	for i := 0; i < 20; i++ {
+		pool.Go(func(ctx context.Context, w io.Writer) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			defer atomic.AddInt32(&running, -1)
+
+			// Later items finish first so that unordered output would be detected
+			time.Sleep(time.Duration(20-i) * time.Millisecond)
+			fmt.Fprintf(w, "item %d start\n", i)
+			fmt.Fprintf(w, "item %d done\n", i)
+			return nil
+		})
+	}
+
+	if err := pool.Wait(); err != nil {
+		t.Fatalf("Wait returned error: %v", err)
+	}
+
+	var expected bytes.Buffer
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&expected, "item %d start\nitem %d done\n", i, i)
+	}
+	if out.String() != expected.String() {
+		t.Errorf("Expected ordered output:\n%s\ngot:\n%s", expected.String(), out.String())
+	}
+	if maxRunning > 4 {
+		t.Errorf("Expected at most 4 concurrent tasks, got %d", maxRunning)
+	}
+}
+
+func TestImportPoolAbortsOnError(t *testing.T) {
+	pool := newImportPool(context.Background(), ImportConfig{Concurrency: 1})
+	pool.out = io.Discard
+
+	fatal := errors.New("fatal")
+	var ran int32
+	submitted := 0
+	for i := 0; i < 10; i++ {
+		err := pool.Go(func(ctx context.Context, w io.Writer) error {
+			atomic.AddInt32(&ran, 1)
+			if i == 2 {
+				return fatal
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+		submitted++
+	}
+
+	if err := pool.Wait(); !errors.Is(err, fatal) {
+		t.Errorf("Expected fatal error, got %v", err)
+	}
+	if submitted >= 10 {
+		t.Errorf("Expected submission to stop after the fatal error")
+	}
+	if ran > 3 {
+		t.Errorf("Expected at most 3 tasks to run, got %d", ran)
+	}
+}
+
+func TestImportPoolCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pool := newImportPool(ctx, ImportConfig{Concurrency: 2})
+	pool.out = io.Discard
+
+	err := pool.Go(func(ctx context.Context, w io.Writer) error {
+		t.Error("Expected task not to run after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from Go, got %v", err)
+	}
+	if err := pool.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from Wait, got %v", err)
+	}
+}
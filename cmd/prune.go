@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ragie/pkg/client"
+)
+
+// markSeen records that path still exists in the import source
+func (r *importRun) markSeen(path string) {
+	if !r.config.Prune {
+		return
+	}
+
+	r.seenMu.Lock()
+	defer r.seenMu.Unlock()
+	if r.seen == nil {
+		r.seen = map[string]bool{}
+	}
+	r.seen[path] = true
+}
+
+// prune deletes the documents matching filter whose path metadata was not seen during
+// the import, i.e. whose source file was removed. Only documents in the namespace of
+// the import are considered, so that other directories or archives imported into the
+// same partition are kept. It must only run after a complete import, as every
+// document of an unvisited path is deleted.
+func (r *importRun) prune(ctx context.Context, filter map[string]interface{}) error {
+	opts := client.ListOptions{
+		Filter:    filter,
+		PageSize:  100,
+		Partition: r.config.Partition,
+	}
+
+	// Collect all stale documents before deleting, as deleting while paging
+	// through the results would shift the cursor
+	var stale []client.Document
+	kept := 0
+	for {
+		resp, err := r.client.ListDocumentsContext(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list documents to prune: %w", err)
+		}
+
+		for _, doc := range resp.Documents {
+			externalID, _ := doc.Metadata["external_id"].(string)
+			if !strings.HasPrefix(externalID, r.config.IDPrefix+":") {
+				continue
+			}
+
+			path, _ := doc.Metadata["path"].(string)
+			if r.seen[path] {
+				kept++
+				continue
+			}
+			stale = append(stale, doc)
+		}
+
+		if resp.Pagination.NextCursor == "" {
+			break
+		}
+		opts.Cursor = resp.Pagination.NextCursor
+	}
+
+	for _, doc := range stale {
+		start := time.Now()
+		path, _ := doc.Metadata["path"].(string)
+		e := event{Name: doc.Name, DocumentID: doc.ID, Message: path}
+		if externalID, ok := doc.Metadata["external_id"].(string); ok {
+			e.ExternalID = externalID
+		}
+
+		if r.config.DryRun {
+			e.Action = actionPruned
+			r.report.Item(stdout, e, "would prune %s: %s\n", doc.ID, path)
+			continue
+		}
+
+		if err := r.client.DeleteDocumentContext(ctx, doc.ID); err != nil && !errors.Is(err, client.ErrNotFound) {
+			e.DurationMS = since(start)
+			if ctx.Err() != nil || isFatalImportError(err) {
+				e.Action = actionFailed
+				e.Error = err.Error()
+				r.report.Item(stdout, e, "")
+				return fmt.Errorf("failed to prune document %s: %w", doc.ID, err)
+			}
+			if err := r.failItem(stdout, e, err, "failed to prune document %s: %v\n", doc.ID, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		e.Action = actionPruned
+		e.DurationMS = since(start)
+		r.report.Item(stdout, e, "pruned %s: %s\n", doc.ID, path)
+	}
+
+	action := "pruned"
+	if r.config.DryRun {
+		action = "would prune"
+	}
+	r.report.Textf(stdout, "prune: %s %d documents, kept %d\n", action, r.report.Count(actionPruned), kept)
+
+	return nil
+}
@@ -1,21 +1,54 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
+
+	"ragie/pkg/client"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 var (
-	dryRun    bool
-	delay     float64
-	partition string
-	mode      string
-	force     bool
-	replace   bool
+	dryRun      bool
+	delay       float64
+	partition   string
+	mode        string
+	force       bool
+	replace     bool
+	baseURL     string
+	timeout     time.Duration
+	maxRetries  int
+	concurrency int
+	rate        float64
+	statePath   string
+	resume      bool
+	syncMode    bool
+	updateMode  bool
+	updateMeta  bool
+	prune       bool
+
+	outputFormat string
+	failFast     bool
+	wait         bool
+	waitTimeout  time.Duration
+	includes     []string
+	excludes     []string
+	postTypes    []string
+	postStatuses []string
+	idPrefix     string
+
+	configPath string
+	profile    string
 )
 
 var rootCmd = &cobra.Command{
@@ -23,28 +56,101 @@ var rootCmd = &cobra.Command{
 	Short: "A CLI tool for importing data into Ragie",
 	Long: `A command line interface for importing various data formats into Ragie,
 including YouTube data, WordPress exports, and ReadmeIO documentation.`,
+	// Errors are printed once by run
+	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// The arguments and flags are valid by now, so later errors are not usage errors
+		cmd.SilenceUsage = true
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	// Cancel in-flight requests and stop imports cleanly on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if code := run(ctx, os.Stderr); code != 0 {
+		os.Exit(code)
 	}
 }
 
+// run executes the command line, prints the error if it failed, and returns the exit status
+func run(ctx context.Context, stderr io.Writer) int {
+	err := rootCmd.ExecuteContext(ctx)
+	if err == nil {
+		return 0
+	}
+	fmt.Fprintf(stderr, "Error: %v\n", err)
+
+	// Let scripts tell a partially failed run apart from one that could not run at all
+	var itemsFailed *ItemsFailedError
+	if errors.As(err, &itemsFailed) {
+		return ExitItemsFailed
+	}
+	return 1
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", DefaultConfigPath(), "Configuration file with named profiles")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Profile of the configuration file to use (default $RAGIE_PROFILE or the file's default_profile)")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print what would happen without making changes")
-	rootCmd.PersistentFlags().Float64Var(&delay, "delay", 2.0, "Delay between imports in seconds")
+	rootCmd.PersistentFlags().Float64Var(&delay, "delay", 0, "Delay between API requests in seconds (deprecated, equivalent to --rate 1/delay)")
+	rootCmd.PersistentFlags().MarkDeprecated("delay", "use --rate instead")
 	rootCmd.PersistentFlags().StringVar(&partition, "partition", "", "Optional partition to use for operations")
+	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", client.BaseURL, "Base URL of the Ragie API")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", client.DefaultTimeout, "How long each API request waits for the response once sent, uploads are not limited (0 disables the timeout)")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 1, "Number of items to process in parallel")
+	rootCmd.PersistentFlags().Float64Var(&rate, "rate", 0, "Maximum number of API requests per second (0 means unlimited)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", OutputText, "Output format: 'text', 'json' (a single document once done) or 'ndjson' (one event per line)")
+	rootCmd.PersistentFlags().BoolVar(&failFast, "fail-fast", false, "Stop at the first item that fails instead of continuing with the others")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", client.DefaultRetryPolicy.MaxRetries, "Maximum number of retries for rate limited or failed API requests")
 }
 
 func initConfig() {
-	apiKey := os.Getenv("RAGIE_API_KEY")
-	if apiKey == "" {
-		fmt.Fprintln(os.Stderr, "Error: RAGIE_API_KEY environment variable must be set")
+	// Flags of all commands, as the settings include the mode of the import command
+	flags := pflag.NewFlagSet("settings", pflag.ContinueOnError)
+	flags.AddFlagSet(rootCmd.PersistentFlags())
+	flags.AddFlagSet(importCmd.PersistentFlags())
+
+	name, err := loadConfig(viper.GetViper(), flags, configPath, profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	viper.Set("api_key", apiKey)
+	if name != "" {
+		fmt.Fprintf(os.Stderr, "Using profile: %s\n", name)
+	}
+
+	if viper.GetString("api_key") == "" {
+		fmt.Fprintln(os.Stderr, "Error: RAGIE_API_KEY environment variable must be set, or api_key in a profile")
+		os.Exit(1)
+	}
+
+	// Resolve the flags that may be set by the environment or the profile
+	baseURL = viper.GetString("base_url")
+	partition = viper.GetString("partition")
+	mode = viper.GetString("mode")
+	delay = viper.GetFloat64("delay")
+	rate = viper.GetFloat64("rate")
+	concurrency = viper.GetInt("concurrency")
+}
+
+// newClient creates an API client from the global flags and configuration
+func newClient() *client.Client {
+	return client.NewClient(viper.GetString("api_key"),
+		client.WithBaseURL(baseURL),
+		client.WithTimeout(timeout),
+		client.WithMaxRetries(maxRetries),
+		client.WithRateLimit(requestRate()),
+	)
+}
+
+// requestRate returns the request rate limit, falling back to the deprecated --delay
+func requestRate() float64 {
+	if rate <= 0 && delay > 0 {
+		return 1 / delay
+	}
+	return rate
 }
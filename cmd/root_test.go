@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// runCommandLine runs the command line args as Execute would, and returns its exit
+// status and everything written to stderr
+func runCommandLine(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	var stderr bytes.Buffer
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(&stderr)
+	rootCmd.SetErr(&stderr)
+	t.Cleanup(func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		if cmd, _, err := rootCmd.Find(args); err == nil {
+			cmd.SilenceUsage = false
+		}
+		// Flags keep their values between runs
+		for _, flags := range []*pflag.FlagSet{rootCmd.PersistentFlags(), importCmd.PersistentFlags()} {
+			flags.Visit(func(flag *pflag.Flag) {
+				flag.Value.Set(flag.DefValue)
+				flag.Changed = false
+			})
+		}
+	})
+
+	code := run(context.Background(), &stderr)
+	return code, stderr.String()
+}
+
+func TestRunPrintsErrorOnce(t *testing.T) {
+	t.Setenv("RAGIE_API_KEY", "test-key")
+	api := newFakeAPI(t)
+	api.failListing()
+	captureStdout(t)
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.md": "a"})
+
+	code, stderr := runCommandLine(t, "import", "files", dir,
+		"--config", filepath.Join(t.TempDir(), "config.yaml"),
+		"--base-url", api.server.URL,
+		"--max-retries", "0")
+
+	if code != 1 {
+		t.Errorf("Expected exit status 1, got %d", code)
+	}
+	if strings.Contains(stderr, "Usage:") {
+		t.Errorf("Expected no usage after a failed import, got %q", stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "Error: ") {
+		t.Errorf("Expected a single error line, got %q", stderr)
+	}
+}
+
+func TestRunPrintsUsageOnInvalidArgs(t *testing.T) {
+	t.Setenv("RAGIE_API_KEY", "test-key")
+
+	code, stderr := runCommandLine(t, "import", "files",
+		"--config", filepath.Join(t.TempDir(), "config.yaml"))
+
+	if code != 1 {
+		t.Errorf("Expected exit status 1, got %d", code)
+	}
+	if !strings.Contains(stderr, "Usage:") {
+		t.Errorf("Expected usage after invalid arguments, got %q", stderr)
+	}
+	if strings.Count(stderr, "Error: ") != 1 {
+		t.Errorf("Expected a single error line, got %q", stderr)
+	}
+}
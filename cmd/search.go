@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"ragie/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchTopK      int
+	searchFilter    string
+	searchWhere     []string
+	searchRerank    bool
+	searchMaxChunks int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the imported documents",
+	Long: `Search the documents of a partition with the Ragie retrievals API and print the
+best matching chunks, with their score, document name and metadata. This is the
+retrieval step of RAG, useful to check that an import answers the questions it
+is meant to.
+
+Filtering:
+  --filter and --where restrict the search to documents whose metadata matches,
+  with the same syntax as 'documents list'.
+
+Output:
+  --output text (default), json or ndjson (one chunk per line).
+
+Example:
+  ragie search "How do I rotate an API key?" --top-k 8 --rerank --where source_type=readmeio`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return err
+		}
+
+		filter, err := parseFilter(searchFilter, searchWhere)
+		if err != nil {
+			return err
+		}
+
+		resp, err := newClient().RetrieveContext(cmd.Context(), args[0], client.RetrieveOptions{
+			TopK:                 searchTopK,
+			Filter:               filter,
+			Rerank:               searchRerank,
+			MaxChunksPerDocument: searchMaxChunks,
+			Partition:            partition,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to search: %w", err)
+		}
+
+		return writeChunks(stdout, outputFormat, resp.ScoredChunks)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntVar(&searchTopK, "top-k", 8, "Maximum number of chunks to return")
+	searchCmd.Flags().StringVar(&searchFilter, "filter", "", "Metadata filter as JSON, e.g. '{\"source_type\": \"files\"}'")
+	searchCmd.Flags().StringArrayVar(&searchWhere, "where", nil, "Metadata equality condition as key=value (repeatable)")
+	searchCmd.Flags().BoolVar(&searchRerank, "rerank", false, "Rerank the chunks by relevance to the query, which is slower but more accurate")
+	searchCmd.Flags().IntVar(&searchMaxChunks, "max-chunks-per-document", 0, "Maximum number of chunks returned from a single document (0 means no limit)")
+}
+
+// writeChunks writes the scored chunks of a search, best first
+func writeChunks(w io.Writer, format string, chunks []client.ScoredChunk) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(chunks, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+
+	case OutputNDJSON:
+		enc := json.NewEncoder(w)
+		for _, chunk := range chunks {
+			if err := enc.Encode(chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		if len(chunks) == 0 {
+			_, err := fmt.Fprintln(w, "No matching chunks")
+			return err
+		}
+		for i, chunk := range chunks {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			metadata, _ := json.Marshal(chunk.DocumentMetadata)
+			fmt.Fprintf(w, "%d. [%.4f] %s (%s)\n", i+1, chunk.Score, chunk.DocumentName, chunk.DocumentID)
+			fmt.Fprintf(w, "   %s\n", metadata)
+			for _, line := range strings.Split(strings.TrimSpace(chunk.Text), "\n") {
+				fmt.Fprintf(w, "   | %s\n", line)
+			}
+		}
+		return nil
+	}
+}
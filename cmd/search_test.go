@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"ragie/pkg/client"
+)
+
+func TestWriteChunks(t *testing.T) {
+	chunks := []client.ScoredChunk{
+		{ID: "chunk-1", Text: "Rotate keys\nfrom the dashboard.\n", Score: 0.8123, DocumentID: "doc-1", DocumentName: "keys.md", DocumentMetadata: map[string]interface{}{"source_type": "readmeio"}},
+		{ID: "chunk-2", Index: 4, Text: "Keys expire.", Score: 0.5, DocumentID: "doc-2", DocumentName: "faq.md", DocumentMetadata: map[string]interface{}{}},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		chunks   []client.ScoredChunk
+		expected string
+	}{
+		{
+			name:   "text",
+			format: OutputText,
+			chunks: chunks,
+			expected: "1. [0.8123] keys.md (doc-1)\n" +
+				"   {\"source_type\":\"readmeio\"}\n" +
+				"   | Rotate keys\n" +
+				"   | from the dashboard.\n" +
+				"\n" +
+				"2. [0.5000] faq.md (doc-2)\n" +
+				"   {}\n" +
+				"   | Keys expire.\n",
+		},
+		{name: "no chunks", format: OutputText, expected: "No matching chunks\n"},
+		{
+			name:   "ndjson",
+			format: OutputNDJSON,
+			chunks: chunks[1:],
+			expected: `{"id":"chunk-2","index":4,"text":"Keys expire.","score":0.5,"document_id":"doc-2",` +
+				`"document_name":"faq.md","document_metadata":{}}` + "\n",
+		},
+		{name: "empty json", format: OutputJSON, chunks: []client.ScoredChunk{}, expected: "[]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeChunks(&buf, tt.format, tt.chunks); err != nil {
+				t.Fatalf("writeChunks returned error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected output:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
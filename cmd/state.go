@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultStatePath is where --resume keeps the import checkpoint unless --state is given
+const DefaultStatePath = ".ragie-import-state.jsonl"
+
+// stateEntry records one imported document. The checkpoint file holds one JSON
+// entry per line so that each completed item is a cheap append, and a crash
+// loses at most the line being written.
+type stateEntry struct {
+	Source      string    `json:"source,omitempty"` // Namespace of the import, e.g. "files:docs"
+	Partition   string    `json:"partition,omitempty"`
+	ExternalID  string    `json:"external_id"`
+	ContentHash string    `json:"content_hash"`
+	DocumentID  string    `json:"document_id"`
+	ImportedAt  time.Time `json:"imported_at"`
+}
+
+// importState is the checkpoint of an import run
+type importState struct {
+	mu      sync.Mutex
+	file    *os.File
+	source  string
+	entries map[string]stateEntry
+}
+
+// openImportState opens the checkpoint at config.StatePath. When resuming, the entries
+// of the previous run are loaded and new entries are appended; otherwise the checkpoint
+// is started over. A checkpoint of another source or partition is neither resumed nor
+// overwritten. A dry run only reads the checkpoint, and a nil state is returned when
+// the path is empty.
+func openImportState(config ImportConfig) (*importState, error) {
+	path := config.StatePath
+	if path == "" {
+		return nil, nil
+	}
+
+	state := &importState{source: config.IDPrefix, entries: map[string]stateEntry{}}
+	if err := state.load(path); err != nil {
+		return nil, err
+	}
+	for _, entry := range state.entries {
+		// Entries written before the source was recorded have none
+		if entry.Source != "" && (entry.Source != config.IDPrefix || entry.Partition != config.Partition) {
+			return nil, fmt.Errorf("state file %s is the checkpoint of another import (%s), pass a different --state", path, describeSource(entry.Source, entry.Partition))
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !config.Resume {
+		state.entries = map[string]stateEntry{}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+
+	if config.DryRun {
+		return state, nil
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file: %v", err)
+	}
+	state.file = file
+
+	return state, nil
+}
+
+func (s *importState) load(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry stateEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A truncated last line is expected after a crash
+			continue
+		}
+		s.entries[stateKey(entry.Partition, entry.ExternalID)] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read state file: %v", err)
+	}
+
+	return nil
+}
+
+// Completed reports whether the document was already imported with the same content
+func (s *importState) Completed(partition, externalID, contentHash string) (stateEntry, bool) {
+	if s == nil {
+		return stateEntry{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[stateKey(partition, externalID)]
+	return entry, ok && entry.ContentHash == contentHash
+}
+
+// Record appends a completed document to the checkpoint
+func (s *importState) Record(partition, externalID, contentHash, documentID string) error {
+	if s == nil {
+		return nil
+	}
+
+	entry := stateEntry{
+		Source:      s.source,
+		Partition:   partition,
+		ExternalID:  externalID,
+		ContentHash: contentHash,
+		DocumentID:  documentID,
+		ImportedAt:  time.Now().UTC(),
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[stateKey(partition, externalID)] = entry
+	if s.file == nil {
+		return nil
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+
+	return nil
+}
+
+// Close closes the checkpoint file
+func (s *importState) Close() error {
+	if s == nil || s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// describeSource returns the source of a checkpoint entry for messages
+func describeSource(source, partition string) string {
+	if partition == "" {
+		return source
+	}
+	return fmt.Sprintf("%s in partition %s", source, partition)
+}
+
+func stateKey(partition, externalID string) string {
+	return partition + "\x00" + externalID
+}
+
+// readContentHash returns the hex encoded SHA-256 of everything read from r, and
+// whether the content is blank, i.e. empty or only whitespace
+func readContentHash(r io.Reader) (string, bool, error) {
+	hash := sha256.New()
+	blank := true
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			hash.Write(buf[:n])
+			if blank && len(bytes.TrimSpace(buf[:n])) > 0 {
+				blank = false
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), blank, nil
+}
+
+// contentHash returns the hex encoded SHA-256 of content
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
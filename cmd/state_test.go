@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportStateResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+
+	state, err := openImportState(ImportConfig{StatePath: path})
+	if err != nil {
+		t.Fatalf("Failed to open state: %v", err)
+	}
+	if err := state.Record("", "docs/a.md", contentHash([]byte("a")), "doc-a"); err != nil {
+		t.Fatalf("Failed to record: %v", err)
+	}
+	if err := state.Record("staging", "docs/b.md", contentHash([]byte("b")), "doc-b"); err != nil {
+		t.Fatalf("Failed to record: %v", err)
+	}
+	state.Close()
+
+	// Simulate a crash in the middle of writing an entry
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open state file: %v", err)
+	}
+	f.WriteString(`{"external_id": "docs/c.md", "content_ha`)
+	f.Close()
+
+	state, err = openImportState(ImportConfig{StatePath: path, Resume: true})
+	if err != nil {
+		t.Fatalf("Failed to reopen state: %v", err)
+	}
+	defer state.Close()
+
+	tests := []struct {
+		name       string
+		partition  string
+		externalID string
+		content    string
+		expected   bool
+	}{
+		{name: "completed", partition: "", externalID: "docs/a.md", content: "a", expected: true},
+		{name: "changed content", partition: "", externalID: "docs/a.md", content: "a2", expected: false},
+		{name: "other partition", partition: "", externalID: "docs/b.md", content: "b", expected: false},
+		{name: "completed in partition", partition: "staging", externalID: "docs/b.md", content: "b", expected: true},
+		{name: "truncated entry", partition: "", externalID: "docs/c.md", content: "c", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := state.Completed(tt.partition, tt.externalID, contentHash([]byte(tt.content)))
+			if ok != tt.expected {
+				t.Errorf("Expected completed=%v, got %v", tt.expected, ok)
+			}
+			if ok && entry.DocumentID == "" {
+				t.Errorf("Expected a document ID for completed entry")
+			}
+		})
+	}
+}
+
+func TestImportStateStartsOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+
+	state, err := openImportState(ImportConfig{StatePath: path})
+	if err != nil {
+		t.Fatalf("Failed to open state: %v", err)
+	}
+	state.Record("", "a.txt", contentHash([]byte("a")), "doc-a")
+	state.Close()
+
+	// Without --resume the previous checkpoint is discarded
+	state, err = openImportState(ImportConfig{StatePath: path})
+	if err != nil {
+		t.Fatalf("Failed to reopen state: %v", err)
+	}
+	state.Close()
+
+	state, err = openImportState(ImportConfig{StatePath: path, Resume: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed to reopen state: %v", err)
+	}
+	if _, ok := state.Completed("", "a.txt", contentHash([]byte("a"))); ok {
+		t.Errorf("Expected checkpoint to be started over")
+	}
+
+	// A dry run never writes the checkpoint
+	state.Record("", "b.txt", contentHash([]byte("b")), "doc-b")
+	state.Close()
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("Expected empty state file after dry run, got %v, %v", info, err)
+	}
+}
+
+func TestImportStateOtherSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+
+	state, err := openImportState(ImportConfig{StatePath: path, IDPrefix: "files:docs"})
+	if err != nil {
+		t.Fatalf("Failed to open state: %v", err)
+	}
+	state.Record("", "files:docs:a.md", contentHash([]byte("a")), "doc-a")
+	state.Close()
+
+	tests := []struct {
+		name   string
+		config ImportConfig
+	}{
+		{name: "other source", config: ImportConfig{StatePath: path, IDPrefix: "zip:docs.zip"}},
+		{name: "other source resumed", config: ImportConfig{StatePath: path, IDPrefix: "zip:docs.zip", Resume: true}},
+		{name: "other partition", config: ImportConfig{StatePath: path, IDPrefix: "files:docs", Partition: "staging"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := openImportState(tt.config); err == nil || !strings.Contains(err.Error(), "files:docs") {
+				t.Errorf("Expected an error naming the other import, got %v", err)
+			}
+		})
+	}
+
+	// The checkpoint of the other import is kept
+	state, err = openImportState(ImportConfig{StatePath: path, IDPrefix: "files:docs", Resume: true})
+	if err != nil {
+		t.Fatalf("Failed to reopen state: %v", err)
+	}
+	defer state.Close()
+	if _, ok := state.Completed("", "files:docs:a.md", contentHash([]byte("a"))); !ok {
+		t.Errorf("Expected the checkpoint to be kept")
+	}
+}
+
+func TestReadContentHash(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedBlank bool
+	}{
+		{name: "empty", content: "", expectedBlank: true},
+		{name: "whitespace", content: " \n\t\n", expectedBlank: true},
+		{name: "text", content: "hello", expectedBlank: false},
+		{name: "text after a buffer of whitespace", content: strings.Repeat(" ", 64*1024) + "x", expectedBlank: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, blank, err := readContentHash(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("readContentHash returned error: %v", err)
+			}
+			if blank != tt.expectedBlank {
+				t.Errorf("Expected blank=%v, got %v", tt.expectedBlank, blank)
+			}
+			if expected := contentHash([]byte(tt.content)); hash != expected {
+				t.Errorf("Expected hash %s, got %s", expected, hash)
+			}
+		})
+	}
+}
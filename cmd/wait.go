@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ragie/pkg/client"
+)
+
+// waitPollInterval is the time between two checks of the documents being processed
+var waitPollInterval = 2 * time.Second
+
+// trackProcessing records the document created for an item to wait for with --wait.
+// If replace is set, the existing documents with its external ID are deleted once it
+// is ready.
+func (r *importRun) trackProcessing(created event, replace bool) {
+	if !r.config.Wait {
+		return
+	}
+
+	r.processingMu.Lock()
+	defer r.processingMu.Unlock()
+	r.processing = append(r.processing, event{ExternalID: created.ExternalID, Name: created.Name, DocumentID: created.DocumentID})
+	if replace {
+		if r.replacing == nil {
+			r.replacing = map[string]bool{}
+		}
+		r.replacing[created.DocumentID] = true
+	}
+}
+
+// waitProcessed polls the documents created by the import until they are ready or
+// failed. Documents that failed, or are still processing after --wait-timeout, are
+// reported as processing_failed once the uploads are done.
+func (r *importRun) waitProcessed(ctx context.Context) error {
+	pending := r.processing
+	if len(pending) == 0 {
+		return nil
+	}
+	r.report.Infof("Waiting for %d documents to be processed...\n", len(pending))
+
+	var deadline <-chan time.Time
+	if r.config.WaitTimeout > 0 {
+		timer := time.NewTimer(r.config.WaitTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	start := time.Now()
+	ready, failed := 0, 0
+	for len(pending) > 0 {
+		var next []event
+		for _, e := range pending {
+			doc, err := r.client.GetDocumentContext(ctx, e.DocumentID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if isFatalImportError(err) {
+					return fmt.Errorf("failed to get document %s: %w", e.DocumentID, err)
+				}
+				if errors.Is(err, client.ErrNotFound) {
+					failed++
+					e.Error = "document not found"
+					if r.replacing[e.DocumentID] {
+						e.Message = "kept existing documents"
+					}
+					if err := r.failProcessing(e, start, "document %s for %s was deleted before it was processed\n", e.DocumentID, e.ExternalID); err != nil {
+						return err
+					}
+					continue
+				}
+				// Keep polling through transient errors until the timeout
+				e.Error = err.Error()
+				next = append(next, e)
+				continue
+			}
+
+			switch doc.Status {
+			case client.DocumentStatusReady:
+				ready++
+				if r.replacing[e.DocumentID] {
+					if err := r.finishReplace(ctx, e, start); err != nil {
+						return err
+					}
+				}
+			case client.DocumentStatusFailed:
+				failed++
+				e.Error = "document processing failed"
+				r.abandonReplace(ctx, &e)
+				if err := r.failProcessing(e, start, "processing failed for document %s: %s\n", e.DocumentID, e.ExternalID); err != nil {
+					return err
+				}
+			default:
+				e.Message = doc.Status
+				e.Error = ""
+				next = append(next, e)
+			}
+		}
+		pending = next
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			for _, e := range pending {
+				failed++
+				if e.Error == "" {
+					e.Error = fmt.Sprintf("still %s after %v", e.Message, r.config.WaitTimeout)
+				}
+				r.abandonReplace(ctx, &e)
+				if err := r.failProcessing(e, start, "document %s not processed after %v: %s\n", e.DocumentID, r.config.WaitTimeout, e.ExternalID); err != nil {
+					return err
+				}
+			}
+			pending = nil
+		case <-time.After(waitPollInterval):
+		}
+	}
+
+	r.report.Textf(stdout, "wait: %d ready, %d failed in %v\n", ready, failed, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// finishReplace deletes the documents replaced by the processed document of e. If they
+// cannot be deleted, the new document is deleted instead.
+func (r *importRun) finishReplace(ctx context.Context, e event, start time.Time) error {
+	replaced, err := r.removePriorDocuments(ctx, e.ExternalID, e.DocumentID, stdout)
+	if err == nil {
+		r.report.Textf(stdout, "replaced %d existing documents: %s\n", len(replaced), e.ExternalID)
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if rollbackErr := r.rollbackReplace(ctx, e.ExternalID, e.DocumentID); rollbackErr != nil {
+		err = fmt.Errorf("%w, and %v", err, rollbackErr)
+	}
+	if isFatalImportError(err) {
+		return fmt.Errorf("failed to replace existing documents for %s: %w", e.ExternalID, err)
+	}
+	e.Error = err.Error()
+	return r.failProcessing(e, start, "failed to replace existing documents for %s: %v\n", e.ExternalID, err)
+}
+
+// abandonReplace deletes the document of e if it was meant to replace existing
+// documents but was not processed, so that the existing documents stay the only version
+func (r *importRun) abandonReplace(ctx context.Context, e *event) {
+	if !r.replacing[e.DocumentID] {
+		return
+	}
+
+	if err := r.rollbackReplace(ctx, e.ExternalID, e.DocumentID); err != nil {
+		e.Error = fmt.Sprintf("%s, and %v", e.Error, err)
+		return
+	}
+	e.Message = "kept existing documents"
+	r.report.Textf(stdout, "deleted new document %s, kept existing documents: %s\n", e.DocumentID, e.ExternalID)
+}
+
+// failProcessing reports a document that was created but not processed. The returned
+// error aborts the import with --fail-fast.
+func (r *importRun) failProcessing(e event, start time.Time, format string, args ...interface{}) error {
+	e.Action = actionProcessingFailed
+	e.DurationMS = since(start)
+	r.report.Item(stdout, e, format, args...)
+
+	if r.config.FailFast {
+		return errFailFast
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -63,7 +64,7 @@ func TestFilesImport(t *testing.T) {
 		Delay:  0,        // No delay for tests
 		Mode:   "hi_res", // Test with hi_res mode
 	}
-	err := cmd.ImportFiles(c, testDir, config)
+	err := cmd.ImportFiles(context.Background(), c, testDir, config)
 	if err != nil {
 		t.Fatalf("Failed to import files: %v", err)
 	}
@@ -74,7 +75,7 @@ func TestFilesImport(t *testing.T) {
 
 	// Check first file
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "file1.txt"},
+		Filter:   map[string]interface{}{"external_id": "files:test_files:file1.txt"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -97,7 +98,7 @@ func TestFilesImport(t *testing.T) {
 
 	// Check nested file
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "subdir/file3.json"},
+		Filter:   map[string]interface{}{"external_id": "files:test_files:subdir/file3.json"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -109,7 +110,7 @@ func TestFilesImport(t *testing.T) {
 
 	// Verify that empty file was not imported
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "empty.txt"},
+		Filter:   map[string]interface{}{"external_id": "files:test_files:empty.txt"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -145,7 +146,7 @@ func TestFilesImportForce(t *testing.T) {
 
 	// Clean up any existing test documents with this external ID
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:force_test:" + testFile},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -154,7 +155,10 @@ func TestFilesImportForce(t *testing.T) {
 	}
 
 	// Create temporary test directory and file
-	tempDir := t.TempDir()
+	tempDir := filepath.Join(t.TempDir(), "force_test")
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
 	tempFilePath := filepath.Join(tempDir, testFile)
 	if err := os.WriteFile(tempFilePath, []byte(testContent), 0644); err != nil {
 		t.Fatalf("Failed to create test file: %v", err)
@@ -169,7 +173,7 @@ func TestFilesImportForce(t *testing.T) {
 		Mode:   "fast",
 	}
 
-	err := cmd.ImportFiles(c, tempDir, config)
+	err := cmd.ImportFiles(context.Background(), c, tempDir, config)
 	if err != nil {
 		t.Fatalf("Failed to import files: %v", err)
 	}
@@ -178,7 +182,7 @@ func TestFilesImportForce(t *testing.T) {
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:force_test:" + testFile},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -190,7 +194,7 @@ func TestFilesImportForce(t *testing.T) {
 
 	// Second import without force - should skip
 	t.Log("Running second files import without force...")
-	err = cmd.ImportFiles(c, tempDir, config)
+	err = cmd.ImportFiles(context.Background(), c, tempDir, config)
 	if err != nil {
 		t.Fatalf("Failed to import files: %v", err)
 	}
@@ -199,7 +203,7 @@ func TestFilesImportForce(t *testing.T) {
 
 	// Verify still only one document
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:force_test:" + testFile},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -212,7 +216,7 @@ func TestFilesImportForce(t *testing.T) {
 	// Third import with force - should create duplicate
 	t.Log("Running third files import with force...")
 	config.Force = true
-	err = cmd.ImportFiles(c, tempDir, config)
+	err = cmd.ImportFiles(context.Background(), c, tempDir, config)
 	if err != nil {
 		t.Fatalf("Failed to import files with force: %v", err)
 	}
@@ -221,7 +225,7 @@ func TestFilesImportForce(t *testing.T) {
 
 	// Verify now two documents exist
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:force_test:" + testFile},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -233,7 +237,7 @@ func TestFilesImportForce(t *testing.T) {
 
 	// Clean up test documents
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:force_test:" + testFile},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -266,7 +270,7 @@ func TestFilesImportReplace(t *testing.T) {
 
 	// Clean up any existing test documents with this external ID
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:replace_test:" + testFile},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -275,7 +279,10 @@ func TestFilesImportReplace(t *testing.T) {
 	}
 
 	// Create temporary test directory and file with first content
-	tempDir := t.TempDir()
+	tempDir := filepath.Join(t.TempDir(), "replace_test")
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
 	tempFilePath := filepath.Join(tempDir, testFile)
 	if err := os.WriteFile(tempFilePath, []byte(testContent1), 0644); err != nil {
 		t.Fatalf("Failed to create test file: %v", err)
@@ -291,7 +298,7 @@ func TestFilesImportReplace(t *testing.T) {
 		Mode:    "fast",
 	}
 
-	err := cmd.ImportFiles(c, tempDir, config)
+	err := cmd.ImportFiles(context.Background(), c, tempDir, config)
 	if err != nil {
 		t.Fatalf("Failed to import files: %v", err)
 	}
@@ -300,7 +307,7 @@ func TestFilesImportReplace(t *testing.T) {
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:replace_test:" + testFile},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -319,7 +326,7 @@ func TestFilesImportReplace(t *testing.T) {
 	// Second import with replace - should replace the existing document
 	t.Log("Running second files import with replace...")
 	config.Replace = true
-	err = cmd.ImportFiles(c, tempDir, config)
+	err = cmd.ImportFiles(context.Background(), c, tempDir, config)
 	if err != nil {
 		t.Fatalf("Failed to import files with replace: %v", err)
 	}
@@ -328,7 +335,7 @@ func TestFilesImportReplace(t *testing.T) {
 
 	// Verify still only one document but with different ID (replaced)
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:replace_test:" + testFile},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -345,7 +352,7 @@ func TestFilesImportReplace(t *testing.T) {
 
 	// Clean up test documents
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "files:replace_test:" + testFile},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -358,10 +365,10 @@ func TestFilesImportReplace(t *testing.T) {
 
 func cleanupFilesTestDocuments(t *testing.T, c *client.Client) {
 	testFiles := []string{
-		"file1.txt",
-		"file2.md",
-		"subdir/file3.json",
-		"empty.txt",
+		"files:test_files:file1.txt",
+		"files:test_files:file2.md",
+		"files:test_files:subdir/file3.json",
+		"files:test_files:empty.txt",
 	}
 
 	for _, path := range testFiles {
@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"archive/zip"
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -39,7 +40,7 @@ func TestReadmeIOImport(t *testing.T) {
 		DryRun: false,
 		Delay:  0, // No delay for tests
 	}
-	err := cmd.ImportReadmeIO(c, "../testdata/readme_sample.zip", config)
+	err := cmd.ImportReadmeIO(context.Background(), c, "../testdata/readme_sample.zip", config)
 	if err != nil {
 		t.Fatalf("Failed to import ReadmeIO data: %v", err)
 	}
@@ -50,7 +51,7 @@ func TestReadmeIOImport(t *testing.T) {
 
 	// Check first document
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "first-doc"},
+		Filter:   map[string]interface{}{"external_id": "readmeio:first-doc"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -73,7 +74,7 @@ func TestReadmeIOImport(t *testing.T) {
 
 	// Check second document
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "second-doc"},
+		Filter:   map[string]interface{}{"external_id": "readmeio:second-doc"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -125,7 +126,7 @@ func TestReadmeIOImportForce(t *testing.T) {
 
 	// Clean up any existing test documents with this external ID
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -163,7 +164,7 @@ This is test content for force flag testing.`
 		Force:  false,
 	}
 
-	err = cmd.ImportReadmeIO(c, tempZip, config)
+	err = cmd.ImportReadmeIO(context.Background(), c, tempZip, config)
 	if err != nil {
 		t.Fatalf("Failed to import ReadmeIO data: %v", err)
 	}
@@ -172,7 +173,7 @@ This is test content for force flag testing.`
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -184,7 +185,7 @@ This is test content for force flag testing.`
 
 	// Second import without force - should skip
 	t.Log("Running second ReadmeIO import without force...")
-	err = cmd.ImportReadmeIO(c, tempZip, config)
+	err = cmd.ImportReadmeIO(context.Background(), c, tempZip, config)
 	if err != nil {
 		t.Fatalf("Failed to import ReadmeIO data: %v", err)
 	}
@@ -193,7 +194,7 @@ This is test content for force flag testing.`
 
 	// Verify still only one document
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -206,7 +207,7 @@ This is test content for force flag testing.`
 	// Third import with force - should create duplicate
 	t.Log("Running third ReadmeIO import with force...")
 	config.Force = true
-	err = cmd.ImportReadmeIO(c, tempZip, config)
+	err = cmd.ImportReadmeIO(context.Background(), c, tempZip, config)
 	if err != nil {
 		t.Fatalf("Failed to import ReadmeIO data with force: %v", err)
 	}
@@ -215,7 +216,7 @@ This is test content for force flag testing.`
 
 	// Verify now two documents exist
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -227,7 +228,7 @@ This is test content for force flag testing.`
 
 	// Clean up test documents
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -258,7 +259,7 @@ func TestReadmeIOImportReplace(t *testing.T) {
 
 	// Clean up any existing test documents with this external ID
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -297,7 +298,7 @@ This is the first version of the test content.`
 		Replace: false,
 	}
 
-	err = cmd.ImportReadmeIO(c, tempZip, config)
+	err = cmd.ImportReadmeIO(context.Background(), c, tempZip, config)
 	if err != nil {
 		t.Fatalf("Failed to import ReadmeIO data: %v", err)
 	}
@@ -306,7 +307,7 @@ This is the first version of the test content.`
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -337,7 +338,7 @@ This is the second version of the test content.`
 	// Second import with replace - should replace the existing document
 	t.Log("Running second ReadmeIO import with replace...")
 	config.Replace = true
-	err = cmd.ImportReadmeIO(c, tempZip, config)
+	err = cmd.ImportReadmeIO(context.Background(), c, tempZip, config)
 	if err != nil {
 		t.Fatalf("Failed to import ReadmeIO data with replace: %v", err)
 	}
@@ -346,7 +347,7 @@ This is the second version of the test content.`
 
 	// Verify still only one document but with different ID (replaced)
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -363,7 +364,7 @@ This is the second version of the test content.`
 
 	// Clean up test documents
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testSlug},
+		Filter:   map[string]interface{}{"external_id": "readmeio:" + testSlug},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -400,7 +401,7 @@ func createReadmeTestZipFile(filename string, files map[string]string) error {
 }
 
 func cleanupReadmeIOTestDocuments(t *testing.T, c *client.Client) {
-	testIDs := []string{"first-doc", "second-doc"}
+	testIDs := []string{"readmeio:first-doc", "readmeio:second-doc"}
 	for _, id := range testIDs {
 		resp, err := c.ListDocuments(client.ListOptions{
 			Filter:   map[string]interface{}{"external_id": id},
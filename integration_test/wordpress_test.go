@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -38,7 +39,7 @@ func TestWordPressImport(t *testing.T) {
 		DryRun: false,
 		Delay:  0, // No delay for tests
 	}
-	err := cmd.ImportWordPress(c, "../testdata/wordpress_sample.xml", config)
+	err := cmd.ImportWordPress(context.Background(), c, "../testdata/wordpress_sample.xml", config)
 	if err != nil {
 		t.Fatalf("Failed to import WordPress data: %v", err)
 	}
@@ -49,7 +50,7 @@ func TestWordPressImport(t *testing.T) {
 
 	// Check first post
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "https://example.com/first-post"},
+		Filter:   map[string]interface{}{"external_id": "wordpress:https://example.com/first-post"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -69,7 +70,7 @@ func TestWordPressImport(t *testing.T) {
 
 	// Check second post
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "https://example.com/second-post"},
+		Filter:   map[string]interface{}{"external_id": "wordpress:https://example.com/second-post"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -128,7 +129,7 @@ func TestWordPressImportForce(t *testing.T) {
 
 	// Clean up any existing test documents with this external ID
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -160,7 +161,7 @@ func TestWordPressImportForce(t *testing.T) {
 		Force:  false,
 	}
 
-	err := cmd.ImportWordPress(c, tempFile, config)
+	err := cmd.ImportWordPress(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import WordPress data: %v", err)
 	}
@@ -169,7 +170,7 @@ func TestWordPressImportForce(t *testing.T) {
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -181,7 +182,7 @@ func TestWordPressImportForce(t *testing.T) {
 
 	// Second import without force - should skip
 	t.Log("Running second WordPress import without force...")
-	err = cmd.ImportWordPress(c, tempFile, config)
+	err = cmd.ImportWordPress(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import WordPress data: %v", err)
 	}
@@ -190,7 +191,7 @@ func TestWordPressImportForce(t *testing.T) {
 
 	// Verify still only one document
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -203,7 +204,7 @@ func TestWordPressImportForce(t *testing.T) {
 	// Third import with force - should create duplicate
 	t.Log("Running third WordPress import with force...")
 	config.Force = true
-	err = cmd.ImportWordPress(c, tempFile, config)
+	err = cmd.ImportWordPress(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import WordPress data with force: %v", err)
 	}
@@ -212,7 +213,7 @@ func TestWordPressImportForce(t *testing.T) {
 
 	// Verify now two documents exist
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -224,7 +225,7 @@ func TestWordPressImportForce(t *testing.T) {
 
 	// Clean up test documents
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -255,7 +256,7 @@ func TestWordPressImportReplace(t *testing.T) {
 
 	// Clean up any existing test documents with this external ID
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -288,7 +289,7 @@ func TestWordPressImportReplace(t *testing.T) {
 		Replace: false,
 	}
 
-	err := cmd.ImportWordPress(c, tempFile, config)
+	err := cmd.ImportWordPress(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import WordPress data: %v", err)
 	}
@@ -297,7 +298,7 @@ func TestWordPressImportReplace(t *testing.T) {
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -326,7 +327,7 @@ func TestWordPressImportReplace(t *testing.T) {
 	// Second import with replace - should replace the existing document
 	t.Log("Running second WordPress import with replace...")
 	config.Replace = true
-	err = cmd.ImportWordPress(c, tempFile, config)
+	err = cmd.ImportWordPress(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import WordPress data with replace: %v", err)
 	}
@@ -335,7 +336,7 @@ func TestWordPressImportReplace(t *testing.T) {
 
 	// Verify still only one document but with different ID (replaced)
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -352,7 +353,7 @@ func TestWordPressImportReplace(t *testing.T) {
 
 	// Clean up test documents
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testURL},
+		Filter:   map[string]interface{}{"external_id": "wordpress:" + testURL},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -365,8 +366,8 @@ func TestWordPressImportReplace(t *testing.T) {
 
 func cleanupWordPressTestDocuments(t *testing.T, c *client.Client) {
 	testURLs := []string{
-		"https://example.com/first-post",
-		"https://example.com/second-post",
+		"wordpress:https://example.com/first-post",
+		"wordpress:https://example.com/second-post",
 		"",
 	}
 	testTitles := []string{
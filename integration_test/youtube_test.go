@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -38,7 +39,7 @@ func TestYouTubeImport(t *testing.T) {
 		DryRun: false,
 		Delay:  0, // No delay for tests
 	}
-	err := cmd.ImportYouTube(c, "../testdata/youtube_sample.json", config)
+	err := cmd.ImportYouTube(context.Background(), c, "../testdata/youtube_sample.json", config)
 	if err != nil {
 		t.Fatalf("Failed to import YouTube data: %v", err)
 	}
@@ -49,7 +50,7 @@ func TestYouTubeImport(t *testing.T) {
 
 	// Check first video
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "test123"},
+		Filter:   map[string]interface{}{"external_id": "youtube:test123"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -66,7 +67,7 @@ func TestYouTubeImport(t *testing.T) {
 
 	// Check second video
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "test456"},
+		Filter:   map[string]interface{}{"external_id": "youtube:test456"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -112,7 +113,7 @@ func TestYouTubeImportForce(t *testing.T) {
 	testVideoID := "force_test_video"
 
 	// Clean up any existing test documents
-	cleanupForceTestDocuments(t, c, []string{testVideoID})
+	cleanupForceTestDocuments(t, c, []string{"youtube:" + testVideoID})
 
 	// Create temporary test file
 	tempFile := filepath.Join(t.TempDir(), "youtube_force_test.json")
@@ -136,7 +137,7 @@ func TestYouTubeImportForce(t *testing.T) {
 		Force:  false,
 	}
 
-	err := cmd.ImportYouTube(c, tempFile, config)
+	err := cmd.ImportYouTube(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import YouTube data: %v", err)
 	}
@@ -145,7 +146,7 @@ func TestYouTubeImportForce(t *testing.T) {
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testVideoID},
+		Filter:   map[string]interface{}{"external_id": "youtube:" + testVideoID},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -158,7 +159,7 @@ func TestYouTubeImportForce(t *testing.T) {
 
 	// Second import without force - should skip
 	t.Log("Running second YouTube import without force...")
-	err = cmd.ImportYouTube(c, tempFile, config)
+	err = cmd.ImportYouTube(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import YouTube data: %v", err)
 	}
@@ -167,7 +168,7 @@ func TestYouTubeImportForce(t *testing.T) {
 
 	// Verify still only one document
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testVideoID},
+		Filter:   map[string]interface{}{"external_id": "youtube:" + testVideoID},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -180,7 +181,7 @@ func TestYouTubeImportForce(t *testing.T) {
 	// Third import with force - should create duplicate
 	t.Log("Running third YouTube import with force...")
 	config.Force = true
-	err = cmd.ImportYouTube(c, tempFile, config)
+	err = cmd.ImportYouTube(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import YouTube data with force: %v", err)
 	}
@@ -189,7 +190,7 @@ func TestYouTubeImportForce(t *testing.T) {
 
 	// Verify now two documents exist
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testVideoID},
+		Filter:   map[string]interface{}{"external_id": "youtube:" + testVideoID},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -218,7 +219,7 @@ func TestYouTubeImportForce(t *testing.T) {
 	}
 
 	// Clean up test documents
-	cleanupForceTestDocuments(t, c, []string{testVideoID})
+	cleanupForceTestDocuments(t, c, []string{"youtube:" + testVideoID})
 }
 
 func TestYouTubeImportReplace(t *testing.T) {
@@ -240,7 +241,7 @@ func TestYouTubeImportReplace(t *testing.T) {
 	testVideoID := "replace_test_video"
 
 	// Clean up any existing test documents
-	cleanupForceTestDocuments(t, c, []string{testVideoID})
+	cleanupForceTestDocuments(t, c, []string{"youtube:" + testVideoID})
 
 	// Create temporary test file with first version
 	tempFile := filepath.Join(t.TempDir(), "youtube_replace_test.json")
@@ -265,7 +266,7 @@ func TestYouTubeImportReplace(t *testing.T) {
 		Replace: false,
 	}
 
-	err := cmd.ImportYouTube(c, tempFile, config)
+	err := cmd.ImportYouTube(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import YouTube data: %v", err)
 	}
@@ -274,7 +275,7 @@ func TestYouTubeImportReplace(t *testing.T) {
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testVideoID},
+		Filter:   map[string]interface{}{"external_id": "youtube:" + testVideoID},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -301,7 +302,7 @@ func TestYouTubeImportReplace(t *testing.T) {
 	// Second import with replace - should replace the existing document
 	t.Log("Running second YouTube import with replace...")
 	config.Replace = true
-	err = cmd.ImportYouTube(c, tempFile, config)
+	err = cmd.ImportYouTube(context.Background(), c, tempFile, config)
 	if err != nil {
 		t.Fatalf("Failed to import YouTube data with replace: %v", err)
 	}
@@ -310,7 +311,7 @@ func TestYouTubeImportReplace(t *testing.T) {
 
 	// Verify still only one document but with different ID (replaced)
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testVideoID},
+		Filter:   map[string]interface{}{"external_id": "youtube:" + testVideoID},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -326,11 +327,11 @@ func TestYouTubeImportReplace(t *testing.T) {
 	}
 
 	// Clean up test documents
-	cleanupForceTestDocuments(t, c, []string{testVideoID})
+	cleanupForceTestDocuments(t, c, []string{"youtube:" + testVideoID})
 }
 
 func cleanupTestDocuments(t *testing.T, c *client.Client) {
-	testIDs := []string{"test123", "test456", "test789"}
+	testIDs := []string{"youtube:test123", "youtube:test456", "youtube:test789"}
 	for _, id := range testIDs {
 		resp, err := c.ListDocuments(client.ListOptions{
 			Filter:   map[string]interface{}{"external_id": id},
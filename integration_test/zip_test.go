@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"archive/zip"
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -49,7 +50,7 @@ func TestZipImport(t *testing.T) {
 		Delay:  0,      // No delay for tests
 		Mode:   "fast", // Test with fast mode
 	}
-	err := cmd.ImportZip(c, zipPath, config)
+	err := cmd.ImportZip(context.Background(), c, zipPath, config)
 	if err != nil {
 		t.Fatalf("Failed to import zip: %v", err)
 	}
@@ -60,7 +61,7 @@ func TestZipImport(t *testing.T) {
 
 	// Check first file
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "file1.txt"},
+		Filter:   map[string]interface{}{"external_id": "zip:test_archive.zip:file1.txt"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -86,7 +87,7 @@ func TestZipImport(t *testing.T) {
 
 	// Check nested file
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": "subdir/file3.json"},
+		Filter:   map[string]interface{}{"external_id": "zip:test_archive.zip:subdir/file3.json"},
 		PageSize: 1,
 	})
 	if err != nil {
@@ -126,7 +127,7 @@ func TestZipImportForce(t *testing.T) {
 
 	// Clean up any existing test documents with this external ID
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "zip:force_test_archive.zip:" + testFile},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -153,7 +154,7 @@ func TestZipImportForce(t *testing.T) {
 		Mode:   "fast",
 	}
 
-	err = cmd.ImportZip(c, zipPath, config)
+	err = cmd.ImportZip(context.Background(), c, zipPath, config)
 	if err != nil {
 		t.Fatalf("Failed to import zip: %v", err)
 	}
@@ -162,7 +163,7 @@ func TestZipImportForce(t *testing.T) {
 
 	// Verify document was created
 	resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "zip:force_test_archive.zip:" + testFile},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -174,7 +175,7 @@ func TestZipImportForce(t *testing.T) {
 
 	// Second import without force - should skip
 	t.Log("Running second zip import without force...")
-	err = cmd.ImportZip(c, zipPath, config)
+	err = cmd.ImportZip(context.Background(), c, zipPath, config)
 	if err != nil {
 		t.Fatalf("Failed to import zip: %v", err)
 	}
@@ -183,7 +184,7 @@ func TestZipImportForce(t *testing.T) {
 
 	// Verify still only one document
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "zip:force_test_archive.zip:" + testFile},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -196,7 +197,7 @@ func TestZipImportForce(t *testing.T) {
 	// Third import with force - should create duplicate
 	t.Log("Running third zip import with force...")
 	config.Force = true
-	err = cmd.ImportZip(c, zipPath, config)
+	err = cmd.ImportZip(context.Background(), c, zipPath, config)
 	if err != nil {
 		t.Fatalf("Failed to import zip with force: %v", err)
 	}
@@ -205,7 +206,7 @@ func TestZipImportForce(t *testing.T) {
 
 	// Verify now two documents exist
 	resp, err = c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "zip:force_test_archive.zip:" + testFile},
 		PageSize: 10,
 	})
 	if err != nil {
@@ -217,7 +218,7 @@ func TestZipImportForce(t *testing.T) {
 
 	// Clean up test documents
 	if resp, err := c.ListDocuments(client.ListOptions{
-		Filter:   map[string]interface{}{"external_id": testFile},
+		Filter:   map[string]interface{}{"external_id": "zip:force_test_archive.zip:" + testFile},
 		PageSize: 100,
 	}); err == nil {
 		for _, doc := range resp.Documents {
@@ -291,10 +292,10 @@ func createTestZipFile(zipPath string) error {
 func cleanupZipTestDocuments(t *testing.T, c *client.Client) {
 	// List of test document IDs to clean up
 	testIDs := []string{
-		"file1.txt",
-		"file2.md",
-		"subdir/file3.json",
-		"empty.txt",
+		"zip:test_archive.zip:file1.txt",
+		"zip:test_archive.zip:file2.md",
+		"zip:test_archive.zip:subdir/file3.json",
+		"zip:test_archive.zip:empty.txt",
 	}
 
 	for _, id := range testIDs {
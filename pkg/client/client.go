@@ -2,19 +2,74 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
 const BaseURL = "https://api.ragie.ai"
 
+const (
+	// DefaultTimeout bounds how long a request waits for the response once it was sent.
+	// Sending the body is not limited, so that large uploads on slow links complete.
+	DefaultTimeout = 5 * time.Minute
+	// DefaultUserAgent is sent with every request unless overridden
+	DefaultUserAgent = "ragie-cli"
+)
+
 type Client struct {
 	apiKey     string
+	baseURL    string
+	userAgent  string
 	httpClient *http.Client
+	timeout    time.Duration
+	retry      RetryPolicy
+	limiter    *RateLimiter
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL points the client at a different API host, e.g. a staging or mock server
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithTimeout sets how long each request waits for the response headers once the
+// request, including its body, was sent; zero disables it. It has no effect with
+// WithTransport or WithHTTPClient.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithTransport replaces the transport used to send requests
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTP client entirely
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
 }
 
 type Mode struct {
@@ -24,9 +79,26 @@ type Mode struct {
 }
 
 type Document struct {
-	ID       string                 `json:"id"`
-	Name     string                 `json:"name"`
-	Metadata map[string]interface{} `json:"metadata"`
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Status     string                 `json:"status,omitempty"`
+	Partition  string                 `json:"partition,omitempty"`
+	ChunkCount int                    `json:"chunk_count,omitempty"`
+	CreatedAt  time.Time              `json:"created_at,omitzero"`
+	UpdatedAt  time.Time              `json:"updated_at,omitzero"`
+}
+
+// Statuses in which a document stays once processing is over. A document goes
+// through intermediate statuses such as "pending", "partitioned" or "indexed" first.
+const (
+	DocumentStatusReady  = "ready"
+	DocumentStatusFailed = "failed"
+)
+
+// Done reports whether the document finished processing, successfully or not
+func (d *Document) Done() bool {
+	return d.Status == DocumentStatusReady || d.Status == DocumentStatusFailed
 }
 
 type ListOptions struct {
@@ -43,14 +115,46 @@ type ListResponse struct {
 	} `json:"pagination"`
 }
 
-func NewClient(apiKey string) *Client {
-	return &Client{
+func NewClient(apiKey string, opts ...Option) *Client {
+	// The http.Client has no overall timeout, which would cancel streamed uploads of
+	// large files. Connecting is bounded by the dial and TLS handshake timeouts of the
+	// default transport, and waiting for the response by ResponseHeaderTimeout.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	c := &Client{
 		apiKey:     apiKey,
-		httpClient: &http.Client{},
+		baseURL:    BaseURL,
+		userAgent:  DefaultUserAgent,
+		httpClient: &http.Client{Transport: transport},
+		timeout:    DefaultTimeout,
+		retry:      DefaultRetryPolicy,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	transport.ResponseHeaderTimeout = c.timeout
+	return c
+}
+
+// newRequest builds an authenticated request against the configured base URL
+func (c *Client) newRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	return req, nil
 }
 
 func (c *Client) CreateDocumentRaw(partition string, name string, data string, metadata map[string]interface{}) (*Document, error) {
+	return c.CreateDocumentRawContext(context.Background(), partition, name, data, metadata)
+}
+
+func (c *Client) CreateDocumentRawContext(ctx context.Context, partition string, name string, data string, metadata map[string]interface{}) (*Document, error) {
 	payload := map[string]interface{}{
 		"name":     name,
 		"data":     data,
@@ -66,23 +170,21 @@ func (c *Client) CreateDocumentRaw(partition string, name string, data string, m
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/documents/raw", BaseURL), bytes.NewBuffer(jsonData))
+	req, err := c.newRequest(ctx, "POST", "/documents/raw", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, false)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return nil, newAPIError(resp)
 	}
 
 	var doc Document
@@ -94,6 +196,10 @@ func (c *Client) CreateDocumentRaw(partition string, name string, data string, m
 }
 
 func (c *Client) ListDocuments(opts ListOptions) (*ListResponse, error) {
+	return c.ListDocumentsContext(context.Background(), opts)
+}
+
+func (c *Client) ListDocumentsContext(ctx context.Context, opts ListOptions) (*ListResponse, error) {
 	query := url.Values{}
 	if opts.Filter != nil {
 		filterJSON, err := json.Marshal(opts.Filter)
@@ -109,25 +215,23 @@ func (c *Client) ListDocuments(opts ListOptions) (*ListResponse, error) {
 		query.Set("cursor", opts.Cursor)
 	}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/documents?%s", BaseURL, query.Encode()), nil)
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("/documents?%s", query.Encode()), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
 	if opts.Partition != "" {
 		req.Header.Set("Partition", opts.Partition)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, true)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return nil, newAPIError(resp)
 	}
 
 	var listResp ListResponse
@@ -138,74 +242,80 @@ func (c *Client) ListDocuments(opts ListOptions) (*ListResponse, error) {
 	return &listResp, nil
 }
 
-func (c *Client) DeleteDocument(id string) error {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/documents/%s", BaseURL, id), nil)
+// GetDocument returns the document with the given ID, including its processing status
+func (c *Client) GetDocument(id string) (*Document, error) {
+	return c.GetDocumentContext(context.Background(), id)
+}
+
+func (c *Client) GetDocumentContext(ctx context.Context, id string) (*Document, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("/documents/%s", url.PathEscape(id)), nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return nil, newAPIError(resp)
 	}
 
-	return nil
+	var doc Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
 }
 
-// CreateDocument uploads a file using multipart form data
-// The mode parameter can be set to "hi_res" for higher quality processing or "fast" for faster processing
-func (c *Client) CreateDocument(partition string, name string, fileData []byte, fileName string, metadata map[string]any, mode any) (*Document, error) {
-	// Create a new multipart writer
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+func (c *Client) DeleteDocument(id string) error {
+	return c.DeleteDocumentContext(context.Background(), id)
+}
 
-	// Add the file
-	part, err := writer.CreateFormFile("file", fileName)
+func (c *Client) DeleteDocumentContext(ctx context.Context, id string) error {
+	req, err := c.newRequest(ctx, "DELETE", fmt.Sprintf("/documents/%s", url.PathEscape(id)), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %v", err)
+		return err
 	}
-	if _, err := part.Write(fileData); err != nil {
-		return nil, fmt.Errorf("failed to write file data: %v", err)
+
+	resp, err := c.do(req, true)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
 
-	// Add the name field
-	if err := writer.WriteField("name", name); err != nil {
-		return nil, fmt.Errorf("failed to write name field: %v", err)
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
 	}
 
+	return nil
+}
+
+// CreateDocument uploads file as a new document using multipart form data. The file
+// is streamed as it is read, so its size does not affect memory usage. A failed upload
+// is only retried if file is an io.Seeker, such as an *os.File or a *bytes.Reader.
+// The mode parameter can be set to "hi_res" for higher quality processing or "fast"
+// for faster processing.
+func (c *Client) CreateDocument(partition string, name string, file io.Reader, fileName string, metadata map[string]any, mode any) (*Document, error) {
+	return c.CreateDocumentContext(context.Background(), partition, name, file, fileName, metadata, mode)
+}
+
+func (c *Client) CreateDocumentContext(ctx context.Context, partition string, name string, file io.Reader, fileName string, metadata map[string]any, mode any) (*Document, error) {
+	// Add the name field
+	fields := []formField{{"name", name}}
+
 	// Add the partition field if provided
 	if partition != "" {
-		if err := writer.WriteField("partition", partition); err != nil {
-			return nil, fmt.Errorf("failed to write partition field: %v", err)
-		}
+		fields = append(fields, formField{"partition", partition})
 	}
 
 	// Add the mode field if provided
-	if mode != nil {
-		switch mode := mode.(type) {
-		case *Mode:
-			modeJSON, err := json.Marshal(mode)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal mode: %v", err)
-			}
-			if err := writer.WriteField("mode", string(modeJSON)); err != nil {
-				return nil, fmt.Errorf("failed to write mode field: %v", err)
-			}
-		case string:
-			if err := writer.WriteField("mode", mode); err != nil {
-				return nil, fmt.Errorf("failed to write mode field: %v", err)
-			}
-		default:
-			return nil, fmt.Errorf("invalid mode type: %T", mode)
-		}
+	fields, err := appendModeField(fields, mode)
+	if err != nil {
+		return nil, err
 	}
 
 	// Add metadata as JSON
@@ -214,36 +324,43 @@ func (c *Client) CreateDocument(partition string, name string, fileData []byte,
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal metadata: %v", err)
 		}
-		if err := writer.WriteField("metadata", string(metadataJSON)); err != nil {
-			return nil, fmt.Errorf("failed to write metadata field: %v", err)
-		}
+		fields = append(fields, formField{"metadata", string(metadataJSON)})
 	}
 
-	// Close the writer
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close writer: %v", err)
+	// Stream the form, with the file last so that the fields are sent up front
+	form := newMultipartStream(fields, file, fileName)
+	length, hasLength := form.Len()
+	body, err := form.Open()
+	if err != nil {
+		return nil, err
 	}
 
 	// Create the request
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/documents", BaseURL), body)
+	req, err := c.newRequest(ctx, "POST", "/documents", body)
 	if err != nil {
+		body.Close()
 		return nil, err
 	}
+	if form.Rewindable() {
+		req.GetBody = form.Open
+	}
+	// Files of known size are not sent chunked, which some proxies reject
+	if hasLength {
+		req.ContentLength = length
+	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Content-Type", form.ContentType())
 	req.Header.Set("Accept", "application/json")
 
 	// Send the request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, false)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return nil, newAPIError(resp)
 	}
 
 	// Parse the response
@@ -254,3 +371,22 @@ func (c *Client) CreateDocument(partition string, name string, fileData []byte,
 
 	return &doc, nil
 }
+
+// appendModeField adds the processing mode, a string or a *Mode, to the fields of a
+// multipart form. A nil mode uses the API default.
+func appendModeField(fields []formField, mode any) ([]formField, error) {
+	switch mode := mode.(type) {
+	case nil:
+		return fields, nil
+	case *Mode:
+		modeJSON, err := json.Marshal(mode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal mode: %v", err)
+		}
+		return append(fields, formField{"mode", string(modeJSON)}), nil
+	case string:
+		return append(fields, formField{"mode", mode}), nil
+	default:
+		return nil, fmt.Errorf("invalid mode type: %T", mode)
+	}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientOptions(t *testing.T) {
+	var gotPath, gotAuth, gotAgent, gotPartition string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotAgent = r.Header.Get("User-Agent")
+		gotPartition = r.Header.Get("Partition")
+		json.NewEncoder(w).Encode(ListResponse{Documents: []Document{{ID: "doc1"}}})
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL+"/"), WithUserAgent("ragie-test"))
+	resp, err := c.ListDocuments(ListOptions{Partition: "staging"})
+	if err != nil {
+		t.Fatalf("ListDocuments returned error: %v", err)
+	}
+
+	if len(resp.Documents) != 1 || resp.Documents[0].ID != "doc1" {
+		t.Errorf("Expected one document doc1, got %+v", resp.Documents)
+	}
+	if gotPath != "/documents" {
+		t.Errorf("Expected path '/documents', got '%s'", gotPath)
+	}
+	if gotAuth != "Bearer test-key" {
+		t.Errorf("Expected authorization 'Bearer test-key', got '%s'", gotAuth)
+	}
+	if gotAgent != "ragie-test" {
+		t.Errorf("Expected user agent 'ragie-test', got '%s'", gotAgent)
+	}
+	if gotPartition != "staging" {
+		t.Errorf("Expected partition 'staging', got '%s'", gotPartition)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientWithTransport(t *testing.T) {
+	var gotURL string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotURL = req.URL.String()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       http.NoBody,
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})
+
+	c := NewClient("test-key", WithTransport(transport))
+	if err := c.DeleteDocument("doc1"); err != nil {
+		t.Fatalf("DeleteDocument returned error: %v", err)
+	}
+
+	if gotURL != BaseURL+"/documents/doc1" {
+		t.Errorf("Expected URL '%s/documents/doc1', got '%s'", BaseURL, gotURL)
+	}
+}
+
+func TestClientContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c := NewClient("test-key", WithBaseURL(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.CreateDocumentRawContext(ctx, "", "name", "data", map[string]interface{}{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// slowReader returns its data a byte at a time with a delay before each read
+type slowReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	n := copy(p[:1], r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.URL.Path == "/documents/slow/raw" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(Document{ID: "doc1"})
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithTimeout(50*time.Millisecond), WithMaxRetries(0))
+
+	// Uploading the body takes longer than the timeout, which only bounds the wait for
+	// the response
+	file := &slowReader{data: []byte("slow upload"), delay: 10 * time.Millisecond}
+	if _, err := c.CreateDocument("", "slow.txt", file, "slow.txt", map[string]any{}, nil); err != nil {
+		t.Errorf("Expected a slow upload to complete, got %v", err)
+	}
+
+	if err := c.UpdateDocumentRaw("slow", "data"); err == nil {
+		t.Errorf("Expected a timeout waiting for the response")
+	}
+}
+
+func TestGetDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/documents/doc1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Document not found"}`))
+			return
+		}
+		w.Write([]byte(`{
+			"id": "doc1",
+			"name": "a.txt",
+			"status": "ready",
+			"partition": "staging",
+			"chunk_count": 12,
+			"created_at": "2025-01-02T03:04:05.123456Z",
+			"updated_at": "2025-01-02T03:05:00Z",
+			"metadata": {"external_id": "a.txt"}
+		}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+	doc, err := c.GetDocument("doc1")
+	if err != nil {
+		t.Fatalf("GetDocument returned error: %v", err)
+	}
+
+	if doc.Status != DocumentStatusReady || !doc.Done() {
+		t.Errorf("Expected a ready document, got status '%s'", doc.Status)
+	}
+	if doc.ChunkCount != 12 {
+		t.Errorf("Expected 12 chunks, got %d", doc.ChunkCount)
+	}
+	if doc.Partition != "staging" {
+		t.Errorf("Expected partition 'staging', got '%s'", doc.Partition)
+	}
+	expected := time.Date(2025, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	if !doc.CreatedAt.Equal(expected) {
+		t.Errorf("Expected created at %v, got %v", expected, doc.CreatedAt)
+	}
+
+	if _, err := c.GetDocument("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
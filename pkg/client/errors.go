@@ -0,0 +1,125 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Sentinel errors matched by APIError through errors.Is
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// maxErrorBody limits how much of an error response is kept in memory
+const maxErrorBody = 64 << 10
+
+// APIError is returned for any non-successful response from the Ragie API
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Status is the HTTP status line, e.g. "404 Not Found"
+	Status string
+	// RequestID identifies the request in Ragie's logs, if the API returned one
+	RequestID string
+	// Detail is the error message parsed from the response body
+	Detail string
+	// Body is the raw response body
+	Body string
+	// Retryable reports whether the same request may succeed if sent again later
+	Retryable bool
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: %s - %s", e.Status, e.Body)
+}
+
+// Is matches the ErrNotFound, ErrUnauthorized and ErrRateLimited sentinels
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// newAPIError builds an APIError from a failed response, consuming its body
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestID:  requestID(resp.Header),
+		Detail:     parseErrorDetail(body),
+		Body:       string(body),
+		Retryable:  isRetryableStatus(resp.StatusCode),
+	}
+	if apiErr.Detail == "" {
+		apiErr.Detail = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
+
+func requestID(header http.Header) string {
+	for _, key := range []string{"X-Request-Id", "Request-Id", "X-Amzn-Requestid"} {
+		if id := header.Get(key); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// parseErrorDetail extracts the message from error bodies of the form
+// {"detail": "..."} or {"detail": [{"msg": "..."}]}
+func parseErrorDetail(body []byte) string {
+	var parsed struct {
+		Detail  json.RawMessage `json:"detail"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return ""
+	}
+
+	var detail string
+	if err := json.Unmarshal(parsed.Detail, &detail); err == nil {
+		return detail
+	}
+
+	var validation []struct {
+		Loc []interface{} `json:"loc"`
+		Msg string        `json:"msg"`
+	}
+	if err := json.Unmarshal(parsed.Detail, &validation); err == nil && len(validation) > 0 {
+		v := validation[0]
+		if len(v.Loc) > 0 {
+			return fmt.Sprintf("%v: %s", v.Loc[len(v.Loc)-1], v.Msg)
+		}
+		return v.Msg
+	}
+
+	if len(parsed.Detail) > 0 {
+		return string(parsed.Detail)
+	}
+	return parsed.Message
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		sentinel  error
+		detail    string
+		retryable bool
+	}{
+		{
+			name:     "not found",
+			status:   http.StatusNotFound,
+			body:     `{"detail": "Document not found"}`,
+			sentinel: ErrNotFound,
+			detail:   "Document not found",
+		},
+		{
+			name:     "unauthorized",
+			status:   http.StatusUnauthorized,
+			body:     `{"detail": "Invalid API key"}`,
+			sentinel: ErrUnauthorized,
+			detail:   "Invalid API key",
+		},
+		{
+			name:      "rate limited",
+			status:    http.StatusTooManyRequests,
+			body:      `not json`,
+			sentinel:  ErrRateLimited,
+			detail:    "Too Many Requests",
+			retryable: true,
+		},
+		{
+			name:   "validation error",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"detail": [{"loc": ["body", "name"], "msg": "field required"}]}`,
+			detail: "name: field required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "req-123")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			c := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+			err := c.DeleteDocument("doc1")
+
+			var apiErr *APIError
+			if !errors.As(fmt.Errorf("wrapped: %w", err), &apiErr) {
+				t.Fatalf("Expected *APIError, got %T: %v", err, err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, apiErr.StatusCode)
+			}
+			if apiErr.RequestID != "req-123" {
+				t.Errorf("Expected request ID 'req-123', got '%s'", apiErr.RequestID)
+			}
+			if apiErr.Detail != tt.detail {
+				t.Errorf("Expected detail '%s', got '%s'", tt.detail, apiErr.Detail)
+			}
+			if apiErr.Retryable != tt.retryable {
+				t.Errorf("Expected retryable %v, got %v", tt.retryable, apiErr.Retryable)
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("Expected errors.Is(err, %v) to be true", tt.sentinel)
+			}
+			for _, other := range []error{ErrNotFound, ErrUnauthorized, ErrRateLimited} {
+				if other != tt.sentinel && errors.Is(err, other) {
+					t.Errorf("Expected errors.Is(err, %v) to be false", other)
+				}
+			}
+		})
+	}
+}
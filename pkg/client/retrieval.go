@@ -0,0 +1,79 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// RetrieveOptions are the optional parameters of a retrieval
+type RetrieveOptions struct {
+	// TopK is the maximum number of chunks returned, the API default is used when 0
+	TopK int `json:"top_k,omitempty"`
+	// Filter restricts the search to documents whose metadata matches
+	Filter map[string]interface{} `json:"filter,omitempty"`
+	// Rerank reorders the chunks by relevance to the query, which is slower
+	Rerank bool `json:"rerank,omitempty"`
+	// MaxChunksPerDocument limits how many chunks of a single document are returned
+	MaxChunksPerDocument int    `json:"max_chunks_per_document,omitempty"`
+	Partition            string `json:"partition,omitempty"`
+}
+
+// ScoredChunk is a chunk of a document matching a retrieval query
+type ScoredChunk struct {
+	ID               string                 `json:"id"`
+	Index            int                    `json:"index"`
+	Text             string                 `json:"text"`
+	Score            float64                `json:"score"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	DocumentID       string                 `json:"document_id"`
+	DocumentName     string                 `json:"document_name"`
+	DocumentMetadata map[string]interface{} `json:"document_metadata"`
+}
+
+type RetrieveResponse struct {
+	ScoredChunks []ScoredChunk `json:"scored_chunks"`
+}
+
+// Retrieve returns the chunks most relevant to query, best first
+func (c *Client) Retrieve(query string, opts RetrieveOptions) (*RetrieveResponse, error) {
+	return c.RetrieveContext(context.Background(), query, opts)
+}
+
+func (c *Client) RetrieveContext(ctx context.Context, query string, opts RetrieveOptions) (*RetrieveResponse, error) {
+	payload := struct {
+		Query string `json:"query"`
+		RetrieveOptions
+	}{query, opts}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, "POST", "/retrievals", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// A retrieval does not change anything, so it is safe to retry
+	resp, err := c.do(req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var retrieveResp RetrieveResponse
+	if err := json.NewDecoder(resp.Body).Decode(&retrieveResp); err != nil {
+		return nil, err
+	}
+
+	return &retrieveResp, nil
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRetrieve(t *testing.T) {
+	var got map[string]interface{}
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Method != "POST" || r.URL.Path != "/retrievals" {
+			t.Errorf("Expected POST /retrievals, got %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		// The first attempt fails to check that the body is sent again on retry
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"scored_chunks": [
+			{"id": "chunk-1", "index": 3, "text": "Hello", "score": 0.82, "document_id": "doc-1",
+			 "document_name": "a.txt", "document_metadata": {"source_type": "files"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy))
+	resp, err := c.Retrieve("hello", RetrieveOptions{
+		TopK:      8,
+		Filter:    map[string]interface{}{"source_type": "files"},
+		Rerank:    true,
+		Partition: "staging",
+	})
+	if err != nil {
+		t.Fatalf("Retrieve returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"query":     "hello",
+		"top_k":     float64(8),
+		"filter":    map[string]interface{}{"source_type": "files"},
+		"rerank":    true,
+		"partition": "staging",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected request %v, got %v", expected, got)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+
+	if len(resp.ScoredChunks) != 1 {
+		t.Fatalf("Expected 1 chunk, got %d", len(resp.ScoredChunks))
+	}
+	chunk := resp.ScoredChunks[0]
+	if chunk.DocumentName != "a.txt" || chunk.Score != 0.82 || chunk.Index != 3 {
+		t.Errorf("Unexpected chunk %+v", chunk)
+	}
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; zero disables retries
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubled on every subsequent retry
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by clients that are not configured with WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+// WithRetryPolicy replaces the retry policy of the client
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithMaxRetries sets the number of retries while keeping the default backoff
+func WithMaxRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.retry.MaxRetries = maxRetries
+	}
+}
+
+// do sends req, retrying transient failures according to the retry policy.
+//
+// Idempotent requests (listing, deleting) are retried on network errors and on
+// 429, 500, 502, 503 and 504 responses. Non-idempotent requests (creates) are only
+// retried when the request provably did not reach the API: connection failures
+// while dialing, and 429 responses which the API returns before doing any work. A
+// 503 may come from a proxy after the API created the document, so it is not retried.
+func (c *Client) do(req *http.Request, idempotent bool) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			body, err := rewindBody(req)
+			if err != nil {
+				closeBody(req)
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		if err := c.limiter.Wait(ctx); err != nil {
+			closeBody(req)
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+
+		canRetry := attempt < c.retry.MaxRetries && (req.Body == nil || req.GetBody != nil)
+		if err != nil {
+			if !canRetry || ctx.Err() != nil || !(idempotent || isDialError(err)) {
+				closeBody(req)
+				return nil, err
+			}
+			if err := sleepContext(ctx, c.backoff(attempt)); err != nil {
+				closeBody(req)
+				return nil, err
+			}
+			continue
+		}
+
+		if !canRetry || !shouldRetryStatus(resp.StatusCode, idempotent) {
+			return resp, nil
+		}
+
+		wait, ok := retryAfter(resp)
+		if !ok {
+			wait = c.backoff(attempt)
+		}
+
+		// Drain the body so the connection can be reused
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := sleepContext(ctx, wait); err != nil {
+			closeBody(req)
+			return nil, err
+		}
+	}
+}
+
+// closeBody closes the body of a request that is not sent again. A streamed body is a
+// pipe, whose writer would otherwise block forever with the file open.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// backoff returns a fully jittered exponential delay for the given attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	if c.retry.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := c.retry.BaseDelay << attempt
+	if delay <= 0 || (c.retry.MaxDelay > 0 && delay > c.retry.MaxDelay) {
+		delay = c.retry.MaxDelay
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// shouldRetryStatus reports whether a response with the given status may be retried.
+// 429 is returned by the rate limiter before the API does any work, so it is safe to
+// retry even for non-idempotent requests.
+func shouldRetryStatus(status int, idempotent bool) bool {
+	if !isRetryableStatus(status) {
+		return false
+	}
+	return idempotent || status == http.StatusTooManyRequests
+}
+
+// retryAfter parses the Retry-After header of 429 and 503 responses, which may be
+// either a number of seconds or an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func rewindBody(req *http.Request) (io.ReadCloser, error) {
+	if req.GetBody == nil {
+		return req.Body, nil
+	}
+	return req.GetBody()
+}
+
+// isDialError reports whether err happened while establishing the connection,
+// meaning the request was never sent
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
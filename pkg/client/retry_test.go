@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  time.Millisecond,
+	MaxDelay:   5 * time.Millisecond,
+}
+
+func TestRetryOnRateLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Document{ID: "doc1"})
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy))
+	doc, err := c.CreateDocumentRaw("", "name", "data", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("CreateDocumentRaw returned error: %v", err)
+	}
+
+	if doc.ID != "doc1" {
+		t.Errorf("Expected document ID 'doc1', got '%s'", doc.ID)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected 3 calls, got %d", got)
+	}
+}
+
+func TestRetryStatusByIdempotency(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		create        bool
+		expectedCalls int32
+	}{
+		{name: "list retried on 502", status: http.StatusBadGateway, create: false, expectedCalls: 4},
+		{name: "create not retried on 500", status: http.StatusInternalServerError, create: true, expectedCalls: 1},
+		{name: "create retried on 429", status: http.StatusTooManyRequests, create: true, expectedCalls: 4},
+		{name: "create not retried on 503", status: http.StatusServiceUnavailable, create: true, expectedCalls: 1},
+		{name: "list not retried on 400", status: http.StatusBadRequest, create: false, expectedCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			c := NewClient("test-key", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy))
+
+			var err error
+			if tt.create {
+				_, err = c.CreateDocument("", "name", strings.NewReader("data"), "file.txt", nil, nil)
+			} else {
+				_, err = c.ListDocuments(ListOptions{})
+			}
+
+			if err == nil {
+				t.Errorf("Expected error, but got none")
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, got)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "seconds", status: http.StatusTooManyRequests, header: "7", expected: 7 * time.Second, ok: true},
+		{name: "past date", status: http.StatusServiceUnavailable, header: "Mon, 02 Jan 2006 15:04:05 GMT", expected: 0, ok: true},
+		{name: "missing", status: http.StatusTooManyRequests, header: "", ok: false},
+		{name: "invalid", status: http.StatusTooManyRequests, header: "soon", ok: false},
+		{name: "ignored for other statuses", status: http.StatusBadGateway, header: "7", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: make(http.Header)}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+
+			wait, ok := retryAfter(resp)
+			if ok != tt.ok {
+				t.Errorf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+			if ok && wait != tt.expected {
+				t.Errorf("Expected wait %v, got %v", tt.expected, wait)
+			}
+		})
+	}
+}
+
+func TestNoRetryWhenDisabled(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy), WithMaxRetries(0))
+	if err := c.DeleteDocument("doc1"); err == nil {
+		t.Errorf("Expected error, but got none")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 call, got %d", got)
+	}
+}
+
+func TestCancelledRequestClosesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request to be sent")
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithRateLimit(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A streamed body, whose writer only stops once the reader is closed
+	pr, pw := io.Pipe()
+	done := make(chan error)
+	go func() {
+		_, err := pw.Write([]byte("file content"))
+		done <- err
+	}()
+
+	req, err := c.newRequest(ctx, "POST", "/documents", pr)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if _, err := c.do(req, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("Expected the writer to fail with a closed pipe, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the body to be closed, but the writer is still blocked")
+	}
+}
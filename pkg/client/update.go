@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// UpdateDocumentFile replaces the file of an existing document, which keeps its ID and
+// is processed again. The file is streamed like in CreateDocument, and a failed upload
+// is only retried if file is an io.Seeker.
+func (c *Client) UpdateDocumentFile(id string, file io.Reader, fileName string, mode any) error {
+	return c.UpdateDocumentFileContext(context.Background(), id, file, fileName, mode)
+}
+
+func (c *Client) UpdateDocumentFileContext(ctx context.Context, id string, file io.Reader, fileName string, mode any) error {
+	fields, err := appendModeField(nil, mode)
+	if err != nil {
+		return err
+	}
+
+	form := newMultipartStream(fields, file, fileName)
+	length, hasLength := form.Len()
+	body, err := form.Open()
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(ctx, "PUT", fmt.Sprintf("/documents/%s/file", url.PathEscape(id)), body)
+	if err != nil {
+		body.Close()
+		return err
+	}
+	if form.Rewindable() {
+		req.GetBody = form.Open
+	}
+	// Files of known size are not sent chunked, which some proxies reject
+	if hasLength {
+		req.ContentLength = length
+	}
+
+	req.Header.Set("Content-Type", form.ContentType())
+	req.Header.Set("Accept", "application/json")
+
+	return c.doUpdate(req)
+}
+
+// UpdateDocumentRaw replaces the text content of an existing document, which keeps its
+// ID and is processed again
+func (c *Client) UpdateDocumentRaw(id string, data string) error {
+	return c.UpdateDocumentRawContext(context.Background(), id, data)
+}
+
+func (c *Client) UpdateDocumentRawContext(ctx context.Context, id string, data string) error {
+	jsonData, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(ctx, "PUT", fmt.Sprintf("/documents/%s/raw", url.PathEscape(id)), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.doUpdate(req)
+}
+
+// PatchDocumentMetadata merges metadata into the metadata of an existing document
+// without processing it again. Keys set to nil are removed. It returns the updated
+// metadata of the document.
+func (c *Client) PatchDocumentMetadata(id string, metadata map[string]interface{}) (map[string]interface{}, error) {
+	return c.PatchDocumentMetadataContext(context.Background(), id, metadata)
+}
+
+func (c *Client) PatchDocumentMetadataContext(ctx context.Context, id string, metadata map[string]interface{}) (map[string]interface{}, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, "PATCH", fmt.Sprintf("/documents/%s/metadata", url.PathEscape(id)), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Applying the same patch twice gives the same metadata
+	resp, err := c.do(req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result struct {
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Metadata, nil
+}
+
+// doUpdate sends a request replacing the content of a document, which is idempotent
+func (c *Client) doUpdate(req *http.Request) error {
+	resp, err := c.do(req, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpdateDocumentFile(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Method != "PUT" || r.URL.Path != "/documents/doc1/file" {
+			t.Errorf("Expected PUT /documents/doc1/file, got %s %s", r.Method, r.URL.Path)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Failed to read file: %v", err)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		if string(content) != "new content" || header.Filename != "a.txt" {
+			t.Errorf("Expected a.txt with 'new content', got %s with '%s'", header.Filename, content)
+		}
+		if mode := r.FormValue("mode"); mode != "hi_res" {
+			t.Errorf("Expected mode 'hi_res', got '%s'", mode)
+		}
+		// The first attempt fails to check that the file is sent again on retry
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy))
+	if err := c.UpdateDocumentFile("doc1", strings.NewReader("new content"), "a.txt", "hi_res"); err != nil {
+		t.Fatalf("UpdateDocumentFile returned error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestUpdateDocumentRaw(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/documents/doc1/raw" {
+			t.Errorf("Expected PUT /documents/doc1/raw, got %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+	if err := c.UpdateDocumentRaw("doc1", "new text"); err != nil {
+		t.Fatalf("UpdateDocumentRaw returned error: %v", err)
+	}
+	if expected := map[string]interface{}{"data": "new text"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected request %v, got %v", expected, got)
+	}
+}
+
+func TestPatchDocumentMetadata(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/documents/doc1/metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Document not found"}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"metadata": {"external_id": "a.txt", "title": "A"}}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+	metadata, err := c.PatchDocumentMetadata("doc1", map[string]interface{}{"title": "A", "draft": nil})
+	if err != nil {
+		t.Fatalf("PatchDocumentMetadata returned error: %v", err)
+	}
+
+	expectedRequest := map[string]interface{}{"metadata": map[string]interface{}{"title": "A", "draft": nil}}
+	if !reflect.DeepEqual(got, expectedRequest) {
+		t.Errorf("Expected request %v, got %v", expectedRequest, got)
+	}
+	expected := map[string]interface{}{"external_id": "a.txt", "title": "A"}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Expected metadata %v, got %v", expected, metadata)
+	}
+
+	if _, err := c.PatchDocumentMetadata("missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
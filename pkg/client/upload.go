@@ -0,0 +1,153 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strings"
+)
+
+// formField is a field of a multipart form, written in order before the file
+type formField struct {
+	name  string
+	value string
+}
+
+// multipartStream streams a multipart form through a pipe, so that the file is read
+// while the request is sent instead of being buffered in memory. If the file is an
+// io.Seeker, the form can be opened again from the start to retry the request.
+type multipartStream struct {
+	fields   []formField
+	file     io.Reader
+	fileName string
+	boundary string
+
+	start  int64 // Offset of the file when the first form was opened
+	reader *io.PipeReader
+	done   chan struct{}
+}
+
+func newMultipartStream(fields []formField, file io.Reader, fileName string) *multipartStream {
+	return &multipartStream{
+		fields:   fields,
+		file:     file,
+		fileName: fileName,
+		// The boundary is fixed so that every opened form matches the Content-Type
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+// ContentType returns the Content-Type header of the form
+func (s *multipartStream) ContentType() string {
+	return "multipart/form-data; boundary=" + s.boundary
+}
+
+// Rewindable reports whether the form can be opened more than once
+func (s *multipartStream) Rewindable() bool {
+	_, ok := s.file.(io.Seeker)
+	return ok
+}
+
+// Len returns the length of the form, and false if it is unknown because the file is
+// not an io.Seeker, in which case the form is sent with chunked encoding. It must be
+// called before the form is opened.
+func (s *multipartStream) Len() (int64, bool) {
+	seeker, ok := s.file.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+
+	// Stdin and pipes are *os.File too, but cannot seek
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return 0, false
+	}
+
+	// The form without the file content, which is all but the file's length
+	var counter byteCounter
+	if err := s.writeForm(&counter, strings.NewReader("")); err != nil {
+		return 0, false
+	}
+
+	return int64(counter) + end - start, true
+}
+
+// Open starts writing the form and returns the reader of the request body. Opening
+// the form again stops the previous writer and seeks the file back to where it
+// started.
+func (s *multipartStream) Open() (io.ReadCloser, error) {
+	if s.done != nil {
+		seeker, ok := s.file.(io.Seeker)
+		if !ok {
+			return nil, fmt.Errorf("cannot resend a file that is not seekable")
+		}
+
+		// The transport may still be writing the previous body, wait until the writer
+		// stopped reading the file
+		s.reader.Close()
+		<-s.done
+
+		if _, err := seeker.Seek(s.start, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind file: %v", err)
+		}
+	} else if seeker, ok := s.file.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file offset: %v", err)
+		}
+		s.start = start
+	}
+
+	pr, pw := io.Pipe()
+	s.reader = pr
+	s.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		pw.CloseWithError(s.write(pw))
+	}(s.done)
+
+	return pr, nil
+}
+
+func (s *multipartStream) write(w io.Writer) error {
+	return s.writeForm(w, s.file)
+}
+
+func (s *multipartStream) writeForm(w io.Writer, file io.Reader) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(s.boundary); err != nil {
+		return err
+	}
+
+	for _, field := range s.fields {
+		if err := writer.WriteField(field.name, field.value); err != nil {
+			return fmt.Errorf("failed to write %s field: %v", field.name, err)
+		}
+	}
+
+	part, err := writer.CreateFormFile("file", s.fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %v", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("failed to write file data: %v", err)
+	}
+
+	return writer.Close()
+}
+
+// byteCounter is a writer that only counts the bytes written to it
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCreateDocumentStreamsForm(t *testing.T) {
+	var got struct {
+		name, partition, mode, metadata, fileName, content string
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("Expected a streamed body of unknown length, got %d", r.ContentLength)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Failed to read file: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+
+		got.name = r.FormValue("name")
+		got.partition = r.FormValue("partition")
+		got.mode = r.FormValue("mode")
+		got.metadata = r.FormValue("metadata")
+		got.fileName = header.Filename
+		got.content = string(content)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Document{ID: "doc1"})
+	}))
+	defer server.Close()
+
+	content := strings.Repeat("0123456789", 100000)
+	c := NewClient("test-key", WithBaseURL(server.URL))
+	// A reader that cannot seek, whose length is unknown
+	file := io.MultiReader(strings.NewReader(content))
+	doc, err := c.CreateDocument("staging", "report", file, "report.txt", map[string]any{"source": "test"}, "fast")
+	if err != nil {
+		t.Fatalf("CreateDocument returned error: %v", err)
+	}
+
+	if doc.ID != "doc1" {
+		t.Errorf("Expected document ID 'doc1', got '%s'", doc.ID)
+	}
+	if got.name != "report" || got.partition != "staging" || got.mode != "fast" || got.fileName != "report.txt" {
+		t.Errorf("Unexpected form fields: %+v", got)
+	}
+	if got.metadata != `{"source":"test"}` {
+		t.Errorf("Expected metadata '{\"source\":\"test\"}', got '%s'", got.metadata)
+	}
+	if got.content != content {
+		t.Errorf("Expected %d bytes of content, got %d", len(content), len(got.content))
+	}
+}
+
+func TestCreateDocumentSendsFileLength(t *testing.T) {
+	var contentLength, received int64
+	var content string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		body, _ := io.ReadAll(r.Body)
+		received = int64(len(body))
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Failed to read file: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		content = string(data)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Document{ID: "doc1"})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte("header\nfile content"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	// Only the rest of the file from its offset is sent
+	file.Seek(int64(len("header\n")), io.SeekStart)
+
+	c := NewClient("test-key", WithBaseURL(server.URL))
+	if _, err := c.CreateDocument("staging", "report", file, "report.txt", map[string]any{"source": "test"}, "fast"); err != nil {
+		t.Fatalf("CreateDocument returned error: %v", err)
+	}
+
+	if contentLength <= 0 {
+		t.Errorf("Expected a Content-Length, got %d", contentLength)
+	}
+	if contentLength != received {
+		t.Errorf("Expected Content-Length %d to match the %d bytes received", contentLength, received)
+	}
+	if content != "file content" {
+		t.Errorf("Expected 'file content', got '%s'", content)
+	}
+}
+
+func TestCreateDocumentRetryRewindsFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		file            func(content string) io.Reader
+		expectedCalls   int
+		expectedSuccess bool
+	}{
+		{
+			name:            "seekable file is resent",
+			file:            func(content string) io.Reader { return strings.NewReader(content) },
+			expectedCalls:   2,
+			expectedSuccess: true,
+		},
+		{
+			name:            "stream is not resent",
+			file:            func(content string) io.Reader { return io.MultiReader(strings.NewReader(content)) },
+			expectedCalls:   1,
+			expectedSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var contents []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				file, _, err := r.FormFile("file")
+				if err != nil {
+					t.Errorf("Failed to read file: %v", err)
+					return
+				}
+				content, _ := io.ReadAll(file)
+
+				mu.Lock()
+				contents = append(contents, string(content))
+				calls := len(contents)
+				mu.Unlock()
+
+				if calls == 1 {
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(Document{ID: "doc1"})
+			}))
+			defer server.Close()
+
+			c := NewClient("test-key", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy))
+			_, err := c.CreateDocument("", "name", tt.file("file content"), "file.txt", nil, nil)
+			if (err == nil) != tt.expectedSuccess {
+				t.Errorf("Expected success=%v, got error %v", tt.expectedSuccess, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(contents) != tt.expectedCalls {
+				t.Fatalf("Expected %d calls, got %d", tt.expectedCalls, len(contents))
+			}
+			for i, content := range contents {
+				if content != "file content" {
+					t.Errorf("Expected full content on attempt %d, got '%s'", i+1, content)
+				}
+			}
+		})
+	}
+}